@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Salvadego/HacTools/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the FlexibleSearch result cache",
+	Long: `Results are only cached when --cache-ttl is given. Entries are keyed by
+address, user, query, maxCount, locale and the result options, and are
+stored below the user cache directory (or $HACTOOLS_CACHE_DIR).`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := cache.New("flexsearch")
+		stats, err := c.Stats()
+		if err != nil {
+			return err
+		}
+
+		ratio := 0.0
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			ratio = float64(stats.Hits) / float64(lookups) * 100
+		}
+
+		fmt.Printf("Directory: %s\n", c.Dir)
+		fmt.Printf("Entries:   %d\n", stats.Entries)
+		fmt.Printf("Size:      %d bytes\n", stats.Bytes)
+		fmt.Printf("Hits:      %d\n", stats.Hits)
+		fmt.Printf("Misses:    %d\n", stats.Misses)
+		fmt.Printf("Writes:    %d\n", stats.Writes)
+		fmt.Printf("Hit ratio: %.1f%%\n", ratio)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached results and reset the statistics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := cache.New("flexsearch").Clear()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed %d cached results\n", removed)
+		return nil
+	},
+}
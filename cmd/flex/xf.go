@@ -3,12 +3,20 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/config"
 	"github.com/Salvadego/HacTools/internal/editor"
 	"github.com/Salvadego/HacTools/internal/flexsearch"
+	"github.com/Salvadego/HacTools/internal/history"
+	"github.com/Salvadego/HacTools/internal/library"
 	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/internal/tui"
 	"github.com/Salvadego/HacTools/models"
 	"github.com/spf13/cobra"
 )
@@ -17,26 +25,46 @@ var (
 	maxCount    int
 	noAnalyze   bool
 	noBlacklist bool
+	useTUI      bool
+	expand      bool
+	keepEmpty   bool
+	columns     []string
+	exclude     []string
+	transform   models.FlexTransformOptions
+	locale      string
+	cacheTTL    time.Duration
+	streamFile  string
+	pageSize    int
+	profileRuns int
 	logLevel    string
 )
 
-var columnBlacklist = []string{
-	"hjmpTS",
-	"createdTS",
-	"modifiedTS",
-	"TypePkString",
-	"OwnerPkString",
-	"aCLTS",
-	"propTS",
-}
-
 var conf options.Config
 
+var libraryConfig = models.LibraryConfig{
+	Dir:          &conf.Library,
+	Extensions:   []string{".sql"},
+	ExecutorFunc: executorFunc,
+}
+
 func init() {
 	options.GetDefaults(rootCmd, &conf)
 	rootCmd.PersistentFlags().IntVarP(&maxCount, "max-count", "m", 10, "Maximum number of results")
 	rootCmd.PersistentFlags().BoolVarP(&noAnalyze, "no-analyze", "A", false, "Do not analyze PK")
 	rootCmd.PersistentFlags().BoolVarP(&noBlacklist, "no-blacklist", "B", false, "Ignore column blacklist")
+	rootCmd.PersistentFlags().StringArrayVar(&columns, "columns", nil, "Only show these columns, in this order (comma separated globs or /regex/, repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&exclude, "exclude", nil, "Hide these columns (comma separated globs or /regex/, repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&keepEmpty, "keep-empty", false, "Keep columns without any value")
+	rootCmd.PersistentFlags().StringArrayVar(&transform.Where, "where", nil, "Only keep rows matching col<op>value, op one of ~ !~ = != < <= > >= (repeatable)")
+	rootCmd.PersistentFlags().StringSliceVar(&transform.GroupBy, "group-by", nil, "Group rows by these columns")
+	rootCmd.PersistentFlags().StringSliceVar(&transform.Agg, "agg", nil, "Aggregations per group: count, sum(col), avg(col), min(col), max(col)")
+	rootCmd.PersistentFlags().StringSliceVar(&transform.Sort, "sort", nil, "Sort rows by col[:desc], in order of precedence")
+	rootCmd.PersistentFlags().StringVar(&locale, "locale", "en", "Locale used for localized attributes")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 0, "Reuse cached results younger than this duration (e.g. 5m, 0 disables the cache)")
+	rootCmd.PersistentFlags().StringVar(&streamFile, "stream", "", "Export all rows as CSV to this file (- for stdout), page by page through the scripting console")
+	rootCmd.PersistentFlags().IntVar(&pageSize, "page-size", 10000, "Rows per page when streaming")
+	rootCmd.PersistentFlags().BoolVarP(&expand, "expand", "E", false, "Expand referenced items inline with their unique attributes")
+	rootCmd.PersistentFlags().BoolVarP(&useTUI, "tui", "T", false, "Browse results in an interactive terminal UI")
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "error", "Log level (debug, info, error, none)")
 
 	editorCommand := editor.CreateEditorCommand(models.EditorConfig{
@@ -48,58 +76,260 @@ func init() {
 	})
 
 	rootCmd.AddCommand(editorCommand)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.Flags().IntVarP(&profileRuns, "runs", "r", 10, "Number of executions")
+	rootCmd.AddCommand(library.CreateLibraryCommands(libraryConfig)...)
 }
 
 var rootCmd = &cobra.Command{
-	Use:          "xf [query or file path]",
+	Use:          "xf [query, snippet name or file path]",
 	Short:        "Execute flexible search queries against Hybris HAC",
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger.SetLogLevel(logger.LogLevelFromString(logLevel))
 
-		var query string
-		arg := args[0]
+		query, err := resolveQuery(args[0])
+		if err != nil {
+			return err
+		}
+
+		return executorFunc(query)
+	},
+}
 
-		if _, err := os.Stat(arg); err == nil {
-			data, err := os.ReadFile(arg)
-			if err != nil {
-				return fmt.Errorf("failed to read script file: %w", err)
-			}
-			query = string(data)
-		} else {
-			query = arg
+func resolveQuery(arg string) (string, error) {
+	var query string
+
+	content, found, err := library.Resolve(libraryConfig, arg)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve snippet: %w", err)
+	}
+
+	if found {
+		query = content
+	} else if _, err := os.Stat(arg); err == nil {
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return "", fmt.Errorf("failed to read script file: %w", err)
+		}
+		query = string(data)
+	} else {
+		query = arg
+	}
+
+	if query == "" {
+		return "", fmt.Errorf("query cannot be empty")
+	}
+
+	return query, nil
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <pk>",
+	Short: "Show all attributes of the item with the given PK",
+	Long: `Resolves the type of the PK with the PK analyzer and prints every attribute
+of the item. Localized attributes are shown once per language and references
+are rendered as Type(PK).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger.SetLogLevel(logger.LogLevelFromString(logLevel))
+
+		client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+		client.PinNode(conf.Node)
+		if conf.Timings {
+			client.Timings = timing.New()
+			defer client.Timings.Print(os.Stderr)
 		}
 
-		if query == "" {
-			return fmt.Errorf("query cannot be empty")
+		if err := client.Login(); err != nil {
+			return fmt.Errorf("failed to login: %w", err)
 		}
 
-		return executorFunc(query)
+		executor := flexsearch.NewFlexSearchExecutor(client)
+		item, err := executor.FetchItem(args[0])
+		if err != nil {
+			return err
+		}
+
+		return executor.DisplayItem(item)
+	},
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile [query, snippet name or file path]",
+	Short: "Execute a query repeatedly and report its timing",
+	Long: `Executes the query --runs times without PK analysis or caching and reports
+the min, median, p95 and max of the round trip and of the server execution
+time, together with the SQL the query was translated to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger.SetLogLevel(logger.LogLevelFromString(logLevel))
+
+		query, err := resolveQuery(args[0])
+		if err != nil {
+			return err
+		}
+
+		client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+		client.PinNode(conf.Node)
+		if conf.Timings {
+			client.Timings = timing.New()
+			defer client.Timings.Print(os.Stderr)
+		}
+
+		executor := flexsearch.NewFlexSearchExecutor(client)
+		profile, err := executor.Profile(query, profileRuns, models.FlexExecuteOptions{
+			MaxCount: maxCount,
+			Locale:   locale,
+		})
+		if err != nil {
+			return err
+		}
+
+		return executor.DisplayProfile(profile)
 	},
 }
 
-func executorFunc(query string) error {
+func executorFunc(query string) (err error) {
+	start := time.Now()
+	entry := models.HistoryEntry{
+		Tool:    "xf",
+		Payload: query,
+		Options: map[string]string{
+			"maxCount":    strconv.Itoa(maxCount),
+			"noAnalyze":   strconv.FormatBool(noAnalyze),
+			"noBlacklist": strconv.FormatBool(noBlacklist),
+			"expand":      strconv.FormatBool(expand),
+			"keepEmpty":   strconv.FormatBool(keepEmpty),
+			"columns":     history.EncodeList(flexsearch.SplitPatterns(columns)),
+			"exclude":     history.EncodeList(flexsearch.SplitPatterns(exclude)),
+			"where":       strings.Join(transform.Where, "\n"),
+			"groupBy":     strings.Join(transform.GroupBy, ","),
+			"agg":         strings.Join(transform.Agg, ","),
+			"sort":        strings.Join(transform.Sort, ","),
+			"locale":      locale,
+			"stream":      streamFile,
+		},
+	}
+	defer func() { history.Record(conf, &entry, start, err) }()
+
+	fileConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+
 	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
-	if err := client.Login(); err != nil {
-		return fmt.Errorf("failed to login: %w", err)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+		defer client.Timings.Print(os.Stderr)
 	}
 
 	executor := flexsearch.NewFlexSearchExecutor(client)
-	result, err := executor.Execute(query, models.FlexExecuteOptions{
-		MaxCount:        maxCount,
-		NoAnalyze:       noAnalyze,
-		ColumnBlacklist: columnBlacklist,
-		NoBlacklist:     noBlacklist,
-	})
+	opts := models.FlexExecuteOptions{
+		MaxCount:  maxCount,
+		NoAnalyze: noAnalyze,
+		Expand:    expand,
+		Columns: flexsearch.ResolveColumns(fileConfig.ColumnsFor(conf.Profile),
+			flexsearch.SplitPatterns(columns), flexsearch.SplitPatterns(exclude), noBlacklist),
+		KeepEmpty: keepEmpty,
+		Locale:    locale,
+		CacheTTL:  cacheTTL,
+	}
+
+	if streamFile != "" {
+		if err := checkStreamFlags(); err != nil {
+			return err
+		}
+		return streamQuery(executor, query, opts.Columns, &entry)
+	}
+
+	result, err := executor.Execute(query, opts)
 
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
 
+	if err := flexsearch.Transform(result, transform); err != nil {
+		return fmt.Errorf("failed to transform results: %w", err)
+	}
+
+	entry.Rows = len(result.ResultList)
+	entry.Outcome = fmt.Sprintf("%d rows", entry.Rows)
+	if result.Cached {
+		entry.Outcome += " (cached)"
+	}
+
+	if useTUI && !flexsearch.IsPipe() && len(result.ResultList) > 0 {
+		return tui.NewBrowser(executor, opts).Browse(conf.Address, result)
+	}
+
 	return executor.DisplayResults(result)
 }
 
+// checkStreamFlags rejects the flags that only apply to displayed results,
+// since streaming writes the rows as they are read.
+func checkStreamFlags() error {
+	var unsupported []string
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"--where", len(transform.Where) > 0},
+		{"--sort", len(transform.Sort) > 0},
+		{"--group-by", len(transform.GroupBy) > 0},
+		{"--agg", len(transform.Agg) > 0},
+		{"--tui", useTUI},
+		{"--expand", expand},
+	} {
+		if flag.set {
+			unsupported = append(unsupported, flag.name)
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("--stream cannot be combined with %s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func streamQuery(executor *flexsearch.FlexSearchExecutor, query string, columns models.ColumnConfig, entry *models.HistoryEntry) error {
+	out := os.Stdout
+	if streamFile != "-" {
+		file, err := os.Create(streamFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	rows, err := executor.Stream(query, out, models.FlexStreamOptions{
+		PageSize: pageSize,
+		Locale:   locale,
+		Columns:  columns,
+		OnProgress: func(rows int) {
+			if streamFile != "-" {
+				fmt.Fprintf(os.Stderr, "\rExported %d rows", rows)
+			}
+		},
+	})
+	if streamFile != "-" {
+		fmt.Fprintln(os.Stderr)
+	}
+
+	entry.Rows = rows
+	if err != nil {
+		return fmt.Errorf("failed to stream query: %w", err)
+	}
+
+	entry.Outcome = fmt.Sprintf("%d rows streamed to %s", rows, streamFile)
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
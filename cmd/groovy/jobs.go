@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pollInterval time.Duration
+	waitTimeout  time.Duration
+	followLogs   bool
+)
+
+func init() {
+	waitCmd.Flags().DurationVar(&pollInterval, "interval", 5*time.Second, "Time between polls")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "Give up after this duration (0 waits forever)")
+	logsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Keep printing new output until the job finishes")
+	logsCmd.Flags().DurationVar(&pollInterval, "interval", 5*time.Second, "Time between polls when following")
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status <job id>",
+	Short: "Show the state of a job started with --async",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executor, err := newJobExecutor(args[0])
+		if err != nil {
+			return err
+		}
+		defer executor.Client.Timings.Print(os.Stderr)
+
+		status, err := executor.JobStatus(args[0])
+		if err != nil {
+			return err
+		}
+
+		if outputFormat == groovy.OutputJSON {
+			return output.JSON(status)
+		}
+
+		fmt.Printf("Job:      %s\n", status.ID)
+		fmt.Printf("State:    %s\n", status.State)
+		if status.State == groovy.JobUnknown {
+			return nil
+		}
+		fmt.Printf("Node:     %s\n", status.Node)
+		fmt.Printf("Started:  %s\n", formatMillis(status.StartedAt))
+		fmt.Printf("Finished: %s\n", formatMillis(status.FinishedAt))
+		fmt.Printf("Elapsed:  %s\n", elapsed(status))
+		fmt.Printf("Output:   %d bytes\n", status.LogSize)
+		return nil
+	},
+}
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <job id>",
+	Short: "Wait for a job started with --async and show its result",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executor, err := newJobExecutor(args[0])
+		if err != nil {
+			return err
+		}
+		defer executor.Client.Timings.Print(os.Stderr)
+
+		result, err := executor.Wait(args[0], pollInterval, waitTimeout)
+		if err != nil {
+			return err
+		}
+
+		return executor.DisplayResults(result)
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <job id>",
+	Short: "Print the output of a job started with --async",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executor, err := newJobExecutor(args[0])
+		if err != nil {
+			return err
+		}
+		defer executor.Client.Timings.Print(os.Stderr)
+
+		var offset int64
+		for {
+			output, err := executor.JobLogs(args[0], offset)
+			if err != nil {
+				return err
+			}
+			fmt.Print(output)
+			offset += int64(len(output))
+
+			if !followLogs {
+				return nil
+			}
+
+			status, err := executor.JobStatus(args[0])
+			if err != nil {
+				return err
+			}
+
+			switch status.State {
+			case groovy.JobUnknown:
+				return fmt.Errorf("job %s not found", args[0])
+			case groovy.JobSucceeded, groovy.JobFailed:
+				if status.LogSize <= offset {
+					return nil
+				}
+				continue
+			}
+
+			time.Sleep(pollInterval)
+		}
+	},
+}
+
+// newJobExecutor logs in to the node that runs a job, since its status and
+// output only exist in the temp directory of that node. The node recorded at
+// submission is used unless --node is given.
+func newJobExecutor(id string) (*groovy.GroovyExecutor, error) {
+	logger.SetLogLevel(logger.LogLevelFromString(logLevel))
+
+	job, err := groovy.LoadJob(id)
+	if err != nil {
+		logger.Info("No local record of job %s, polling through the configured node", id)
+		return newExecutor(conf.Node)
+	}
+
+	if job.Profile != "" && job.Profile != conf.Profile {
+		logger.Info("Job %s was submitted to profile %s", id, job.Profile)
+	}
+
+	node := conf.Node
+	if node == "" {
+		node = job.Route
+	}
+	if node == "" && job.Address != conf.Address {
+		node = job.Address
+	}
+	return newExecutor(node)
+}
+
+// newExecutor logs in for the subcommands, pinned to node when it is set.
+// Callers print the timings of the client when they are done.
+func newExecutor(node string) (*groovy.GroovyExecutor, error) {
+	logger.SetLogLevel(logger.LogLevelFromString(logLevel))
+
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(node)
+	if conf.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	return newGroovyExecutor(client), nil
+}
+
+func displayJob(job *models.GroovyJob) error {
+	if outputFormat == groovy.OutputJSON {
+		return output.JSON(map[string]string{"id": job.ID, "state": groovy.JobRunning})
+	}
+
+	if outputFormat == groovy.OutputRaw {
+		fmt.Println(job.ID)
+		return nil
+	}
+
+	fmt.Printf("Submitted job %s\n\n", job.ID)
+	fmt.Printf("  xg status %s\n", job.ID)
+	fmt.Printf("  xg logs -f %s\n", job.ID)
+	fmt.Printf("  xg wait %s\n", job.ID)
+	return nil
+}
+
+func formatMillis(millis int64) string {
+	if millis == 0 {
+		return "-"
+	}
+	return time.UnixMilli(millis).Format("2006-01-02 15:04:05")
+}
+
+func elapsed(status *models.GroovyJobStatus) string {
+	if status.StartedAt == 0 {
+		return "-"
+	}
+
+	end := status.FinishedAt
+	if end == 0 {
+		end = time.Now().UnixMilli()
+	}
+	return (time.Duration(end-status.StartedAt) * time.Millisecond).Round(time.Second).String()
+}
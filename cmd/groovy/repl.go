@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	replPrompt             = "groovy> "
+	replContinuationPrompt = "   ...> "
+)
+
+const replHelp = `Enter Groovy statements; unclosed brackets and a trailing \ continue the
+input on the next line. Variables, imports, methods and classes survive
+between inputs.
+
+  :commit on|off   Execute the following inputs with or without commit
+  :type <type>     Switch the script type (groovy, javascript, beanshell)
+  :load <file>     Execute a file in the session
+  :vars            List the session variables
+  :defs            Show the imports and definitions replayed with every input
+  :reset           Forget all variables and definitions
+  :history         Show the inputs of this session
+  :help            Show this help
+  :quit            Leave the REPL (or Ctrl-D)
+`
+
+type lineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// plainReader reads lines without prompts or editing when stdin is not a
+// terminal.
+type plainReader struct {
+	reader *bufio.Reader
+}
+
+func (r *plainReader) ReadLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func (r *plainReader) SetPrompt(string) {}
+
+// confirmFunc guards switching commit on from inside the REPL.
+type confirmFunc func(action string) error
+
+func confirmCommit(action string) error {
+	return guard.Confirm(conf, action, "")
+}
+
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Start an interactive Groovy shell",
+	Long: `Starts an interactive shell on a logged-in HAC session. Each input is a
+separate console request, but variables are kept on the server between inputs
+and imports, methods and classes are replayed, so the session behaves like a
+persistent shell. Top level declarations such as "def x = 1" become session
+variables.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if commit {
+			if err := guard.Confirm(conf, "start a REPL with commit", ""); err != nil {
+				return err
+			}
+		}
+
+		executor, err := newExecutor(conf.Node)
+		if err != nil {
+			return err
+		}
+		defer executor.Client.Timings.Print(os.Stderr)
+
+		session, err := groovy.NewSession(executor)
+		if err != nil {
+			return err
+		}
+		session.ScriptType = strings.ToLower(scriptType)
+		session.Commit = commit
+		session.IncludePath = includeDirs()
+
+		fd := int(os.Stdin.Fd())
+		interactive := term.IsTerminal(fd)
+		var state *term.State
+		if interactive {
+			if state, err = term.MakeRaw(fd); err != nil {
+				return fmt.Errorf("failed to set up terminal: %w", err)
+			}
+		}
+
+		// The variables of the session are dropped on the server however the
+		// REPL is left: :quit, Ctrl-D, Ctrl-C or a signal.
+		var leave sync.Once
+		cleanup := func() {
+			leave.Do(func() {
+				if interactive {
+					term.Restore(fd, state)
+				}
+				if err := session.Reset(); err != nil {
+					logger.Error("%v", err)
+				}
+			})
+		}
+		defer cleanup()
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			cleanup()
+			os.Exit(130)
+		}()
+
+		if !interactive {
+			return runREPL(session, &plainReader{reader: guard.Stdin}, os.Stdout, confirmCommit)
+		}
+
+		// The confirmation reads a line from stdin, which needs the
+		// terminal in its normal mode.
+		confirm := func(action string) error {
+			if err := term.Restore(fd, state); err != nil {
+				return fmt.Errorf("failed to restore terminal: %w", err)
+			}
+			confirmErr := confirmCommit(action)
+			if state, err = term.MakeRaw(fd); err != nil {
+				return fmt.Errorf("failed to set up terminal: %w", err)
+			}
+			return confirmErr
+		}
+
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{guard.Stdin, os.Stdout}, replPrompt)
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			terminal.SetSize(width, height)
+		}
+
+		fmt.Fprintf(terminal, "Connected to %s. Type :help for commands.\n", conf.Address)
+		return runREPL(session, terminal, terminal, confirm)
+	},
+}
+
+func runREPL(session *groovy.Session, reader lineReader, w io.Writer, confirm confirmFunc) error {
+	var inputs []string
+
+	for {
+		reader.SetPrompt(replPrompt)
+		input, err := reader.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for groovy.Incomplete(input) {
+			reader.SetPrompt(replContinuationPrompt)
+			line, err := reader.ReadLine()
+			if err != nil {
+				break
+			}
+			input += "\n" + line
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		inputs = append(inputs, input)
+
+		if strings.HasPrefix(input, ":") {
+			quit, err := replCommand(session, input, inputs, w, confirm)
+			if err != nil {
+				fmt.Fprintf(w, "error: %v\n", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		resp, err := session.Eval(input, "")
+		if err != nil {
+			fmt.Fprintf(w, "error: %v\n", err)
+			continue
+		}
+		printREPLResponse(w, resp)
+	}
+}
+
+func replCommand(session *groovy.Session, input string, inputs []string, w io.Writer, confirm confirmFunc) (bool, error) {
+	fields := strings.Fields(input)
+	arg := strings.TrimSpace(strings.TrimPrefix(input, fields[0]))
+
+	switch fields[0] {
+	case ":quit", ":q", ":exit":
+		return true, nil
+	case ":help", ":h":
+		fmt.Fprint(w, replHelp)
+	case ":commit":
+		switch arg {
+		case "on":
+			if err := confirm("run REPL inputs with commit"); err != nil {
+				return false, err
+			}
+			session.Commit = true
+		case "off":
+			session.Commit = false
+		case "":
+		default:
+			return false, fmt.Errorf("usage: :commit on|off")
+		}
+		fmt.Fprintf(w, "commit is %s\n", onOff(session.Commit))
+	case ":type":
+		switch arg {
+		case "groovy", "javascript", "beanshell":
+			session.ScriptType = arg
+		case "":
+		default:
+			return false, fmt.Errorf("invalid script type: %s (must be groovy, javascript, or beanshell)", arg)
+		}
+		fmt.Fprintf(w, "script type is %s\n", session.ScriptType)
+	case ":load":
+		if arg == "" {
+			return false, fmt.Errorf("usage: :load <file>")
+		}
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return false, fmt.Errorf("failed to read script file: %w", err)
+		}
+		resp, err := session.Eval(string(data), arg)
+		if err != nil {
+			return false, err
+		}
+		printREPLResponse(w, resp)
+	case ":vars":
+		vars, err := session.Vars()
+		if err != nil {
+			return false, err
+		}
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s: %s\n", name, vars[name])
+		}
+	case ":defs":
+		for _, line := range session.Definitions() {
+			fmt.Fprintln(w, line.Text)
+		}
+	case ":reset":
+		if err := session.Reset(); err != nil {
+			return false, err
+		}
+		fmt.Fprintln(w, "session reset")
+	case ":history":
+		for i, previous := range inputs[:len(inputs)-1] {
+			fmt.Fprintf(w, "%4d  %s\n", i+1, strings.ReplaceAll(previous, "\n", "\n      "))
+		}
+	default:
+		return false, fmt.Errorf("unknown command %s, type :help for commands", fields[0])
+	}
+
+	return false, nil
+}
+
+func printREPLResponse(w io.Writer, resp *models.GroovyResponse) {
+	if output := strings.TrimRight(resp.ScriptResult, "\n"); output != "" {
+		fmt.Fprintln(w, output)
+	}
+
+	if resp.StacktraceText != "" {
+		if diagnostic, ok := groovy.Diagnose(resp.StacktraceText, resp.SourceMap); ok {
+			fmt.Fprintln(w, diagnostic)
+			fmt.Fprint(w, diagnostic.Snippet(resp.SourceMap))
+		} else {
+			message, _, _ := strings.Cut(strings.TrimSpace(resp.StacktraceText), "\n")
+			fmt.Fprintln(w, message)
+		}
+		return
+	}
+
+	if resp.ExecutionResult != "" {
+		fmt.Fprintf(w, "===> %s\n", resp.ExecutionResult)
+	}
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/editor"
+)
+
+type scriptLanguage struct {
+	Extension string
+	Template  string
+}
+
+// scriptLanguages holds the file extension and starter template of every
+// script type. Templates can be overridden with templates/<type><extension>
+// in the config dir.
+var scriptLanguages = map[string]scriptLanguage{
+	"groovy": {
+		Extension: ".groovy",
+		Template: `// Groovy script for the HAC scripting console.
+// Spring beans are bound by name (modelService, flexibleSearchService, ...).
+import de.hybris.platform.servicelayer.search.FlexibleSearchQuery
+
+def query = new FlexibleSearchQuery("SELECT {pk} FROM {Product}")
+query.setCount(10)
+
+flexibleSearchService.search(query).result.each { println it }
+`,
+	},
+	"javascript": {
+		Extension: ".js",
+		Template: `// JavaScript for the HAC scripting console.
+// Spring beans are looked up through the application context.
+var FlexibleSearchQuery = Packages.de.hybris.platform.servicelayer.search.FlexibleSearchQuery;
+var flexibleSearchService = spring.getBean("flexibleSearchService");
+
+var query = new FlexibleSearchQuery("SELECT {pk} FROM {Product}");
+query.setCount(10);
+
+var result = flexibleSearchService.search(query).getResult();
+for (var i = 0; i < result.size(); i++) {
+    print(result.get(i));
+}
+`,
+	},
+	"beanshell": {
+		Extension: ".bsh",
+		Template: `// BeanShell script for the HAC scripting console.
+// Spring beans are looked up through the application context.
+import de.hybris.platform.servicelayer.search.FlexibleSearchQuery;
+
+flexibleSearchService = spring.getBean("flexibleSearchService");
+
+query = new FlexibleSearchQuery("SELECT {pk} FROM {Product}");
+query.setCount(10);
+
+for (item : flexibleSearchService.search(query).getResult()) {
+    print(item);
+}
+`,
+	},
+}
+
+// editorTemplate resolves the editor file and starter content from the
+// effective --type.
+func editorTemplate() (string, string, error) {
+	name := strings.ToLower(scriptType)
+	language, ok := scriptLanguages[name]
+	if !ok {
+		return "", "", fmt.Errorf("invalid script type: %s (must be groovy, javascript, or beanshell)", scriptType)
+	}
+
+	content, err := editor.Template(name+language.Extension, language.Template)
+	if err != nil {
+		return "", "", err
+	}
+
+	return name + "-script-*" + language.Extension, content, nil
+}
@@ -1,50 +1,95 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/cluster"
+	"github.com/Salvadego/HacTools/internal/config"
 	"github.com/Salvadego/HacTools/internal/editor"
 	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/history"
+	"github.com/Salvadego/HacTools/internal/library"
 	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/internal/timing"
 	"github.com/Salvadego/HacTools/models"
 	"github.com/spf13/cobra"
 )
 
 var (
-	commit            bool
-	scriptType        string
-	logLevel          string
-	scriptFilePattern = map[string]string{
-		"groovy":     "groovy-script-*.groovy",
-		"javascript": "js-script-*.js",
-		"branshell":  "branshell-script-*.branshell",
-	}
+	commit         bool
+	scriptType     string
+	logLevel       string
+	varPairs       []string
+	varsFile       string
+	includePath    []string
+	scriptPath     string
+	fullStacktrace bool
+	outputFormat   string
+	parseJSON      bool
+	dryRun         bool
+	async          bool
+	allNodes       bool
+	// snippetParams are the resolved parameters of the groovy library
+	// snippet being run, bound as variables unless given with --var.
+	snippetParams map[string]string
 )
 
 var conf options.Config
 
+var libraryConfig = models.LibraryConfig{
+	Dir:          &conf.Library,
+	Extensions:   []string{".groovy", ".js", ".bsh"},
+	ExecutorFunc: executorFunc,
+}
+
+// snippetTypes maps the extensions of library snippets to script types.
+var snippetTypes = map[string]string{
+	".groovy": "groovy",
+	".js":     "javascript",
+	".bsh":    "beanshell",
+}
+
 func init() {
 	options.GetDefaults(rootCmd, &conf)
+	libraryConfig.SelectFunc = selectSnippet
+	libraryConfig.BindFunc = bindSnippet
 	rootCmd.PersistentFlags().BoolVarP(&commit, "commit", "c", false, "Execute with commit")
 	rootCmd.PersistentFlags().StringVarP(&scriptType, "type", "t", "groovy", "Script type (groovy, javascript, beanshell)")
+	rootCmd.PersistentFlags().StringArrayVarP(&varPairs, "var", "v", nil, "Bind a script variable as name=value (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&varsFile, "vars-file", "", "Bind the script variables of this YAML file")
+	rootCmd.PersistentFlags().StringSliceVarP(&includePath, "include-path", "I", defaultIncludePath(), "Directories searched for //#include files (default: $HACTOOLS_INCLUDE_PATH)")
+	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "Roll the script back and report the items it would have changed")
+	rootCmd.PersistentFlags().BoolVarP(&async, "async", "a", false, "Run the script in the background on the server and print a job id")
+	rootCmd.PersistentFlags().BoolVar(&allNodes, "all-nodes", false, "Run the script on every cluster node and show the result of each")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", groovy.OutputText, "Output format (text, json, raw)")
+	rootCmd.PersistentFlags().BoolVarP(&parseJSON, "parse-json", "j", false, "Parse the script result as JSON")
+	rootCmd.PersistentFlags().BoolVar(&fullStacktrace, "full-stacktrace", false, "Print stack traces without collapsing framework frames")
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "error", "Log level (debug, info, error, none)")
 
 	editorCommand := editor.CreateEditorCommand(models.EditorConfig{
-		FilePattern:    scriptFilePattern[strings.ToLower(scriptType)],
-		InitialContent: "// Enter your Groovy script here\n\n",
+		TemplateFunc: editorTemplate,
 		ExecutorFunc: executorFunc,
-		CustomFlags: []func(*cobra.Command){},
+		CustomFlags:  []func(*cobra.Command){},
 	})
 
 	rootCmd.AddCommand(editorCommand)
+	rootCmd.AddCommand(statusCmd, waitCmd, logsCmd)
+	rootCmd.AddCommand(replCmd)
+	rootCmd.AddCommand(library.CreateLibraryCommands(libraryConfig)...)
 }
 
 var rootCmd = &cobra.Command{
-	Use:          "xg [script or file path]",
+	Use:          "xg [script, snippet name or file path]",
 	Short:        "Execute Groovy scripts against Hybris HAC",
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
@@ -53,47 +98,221 @@ var rootCmd = &cobra.Command{
 		var script string
 		arg := args[0]
 
-		if _, err := os.Stat(arg); err == nil {
+		content, found, err := library.Resolve(libraryConfig, arg)
+		if err != nil {
+			return fmt.Errorf("failed to resolve snippet: %w", err)
+		}
+
+		if found {
+			script = content
+		} else if _, err := os.Stat(arg); err == nil {
 			data, err := os.ReadFile(arg)
 			if err != nil {
 				return fmt.Errorf("failed to read script file: %w", err)
 			}
 			script = string(data)
+			scriptPath = arg
 		} else {
 			script = arg
 		}
 
-        return executorFunc(script)
+		return executorFunc(script)
 	},
 }
 
-func executorFunc(script string) error {
+func executorFunc(script string) (err error) {
 	scriptType = strings.ToLower(scriptType)
+
+	start := time.Now()
+	entry := models.HistoryEntry{
+		Tool:    "xg",
+		Payload: script,
+		Options: map[string]string{
+			"scriptType":  scriptType,
+			"commit":      strconv.FormatBool(commit),
+			"dryRun":      strconv.FormatBool(dryRun),
+			"async":       strconv.FormatBool(async),
+			"allNodes":    strconv.FormatBool(allNodes),
+			"path":        scriptPath,
+			"includePath": strings.Join(includeDirs(), string(filepath.ListSeparator)),
+		},
+	}
+	defer func() { history.Record(conf, &entry, start, err) }()
+
+	var vars map[string]any
+	if len(varPairs) > 0 || varsFile != "" || snippetParams != nil {
+		vars, err = groovy.LoadVars(varsFile, varPairs)
+		if err != nil {
+			return err
+		}
+		for name, value := range snippetParams {
+			if _, ok := vars[name]; !ok {
+				vars[name] = groovy.ParseValue(value)
+			}
+		}
+
+		encodedVars, err := json.Marshal(vars)
+		if err != nil {
+			return fmt.Errorf("failed to encode variables: %w", err)
+		}
+		entry.Options["vars"] = string(encodedVars)
+	}
+
 	if scriptType != "groovy" && scriptType != "javascript" && scriptType != "beanshell" {
 		return fmt.Errorf("invalid script type: %s (must be groovy, javascript, or beanshell)", scriptType)
 	}
 
-	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
-	if err := client.Login(); err != nil {
-		return fmt.Errorf("failed to login: %w", err)
+	// History keeps the script with its includes inlined, so that a rerun
+	// runs the same code even when the included files changed or moved.
+	if scriptType == "groovy" {
+		expanded, _, err := groovy.Include(script, scriptPath, includeDirs())
+		if err != nil {
+			return fmt.Errorf("failed to resolve includes: %w", err)
+		}
+		entry.Payload = expanded
 	}
 
-	executor := groovy.NewGroovyExecutor(client)
-	result, err := executor.Execute(script, models.GroovyExecuteOptions{
-		ScriptType: scriptType,
-		Commit:     commit,
-	})
+	if dryRun && commit {
+		return fmt.Errorf("--dry-run and --commit cannot be combined")
+	}
+
+	if allNodes && async {
+		return fmt.Errorf("--all-nodes and --async cannot be combined")
+	}
+
+	if allNodes && conf.Node != "" {
+		return fmt.Errorf("--all-nodes and --node cannot be combined")
+	}
+
+	if commit {
+		if err := guard.Confirm(conf, "run a script with commit", script); err != nil {
+			return err
+		}
+	}
+
+	var timings *timing.Timings
+	if conf.Timings {
+		timings = timing.New()
+		defer timings.Print(os.Stderr)
+	}
+
+	login := func(pin string) (*client.HACClient, error) {
+		client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+		client.PinNode(pin)
+		client.Timings = timings
+		if err := client.Login(); err != nil {
+			return nil, fmt.Errorf("failed to login: %w", err)
+		}
+		return client, nil
+	}
 
+	opts := models.GroovyExecuteOptions{
+		ScriptType:  scriptType,
+		Commit:      commit,
+		Vars:        vars,
+		Path:        scriptPath,
+		IncludePath: includeDirs(),
+		DryRun:      dryRun,
+	}
+
+	if allNodes {
+		fileConfig, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		sessions, missing, err := cluster.Sessions(login, fileConfig.NodesFor(conf.Profile))
+		if err != nil {
+			return err
+		}
+
+		results := cluster.Execute(sessions, missing, func(c *client.HACClient) (*models.GroovyResponse, error) {
+			return newGroovyExecutor(c).Execute(script, opts)
+		})
+
+		entry.Outcome = fmt.Sprintf("%d nodes", len(results))
+		return newGroovyExecutor(nil).DisplayNodeResults(results)
+	}
+
+	client, err := login(conf.Node)
+	if err != nil {
+		return err
+	}
+
+	executor := newGroovyExecutor(client)
+	if async {
+		job, err := executor.Submit(script, opts)
+		if err != nil {
+			return fmt.Errorf("failed to submit script: %w", err)
+		}
+
+		entry.Outcome = "submitted job " + job.ID
+		return displayJob(job)
+	}
+
+	result, err := executor.Execute(script, opts)
 	if err != nil {
 		return fmt.Errorf("failed to execute script: %w", err)
 	}
 
+	if result.Changes != nil {
+		entry.Outcome = fmt.Sprintf("dry run, %d changes", len(result.Changes))
+	}
+
 	return executor.DisplayResults(result)
 }
 
+// selectSnippet runs a library snippet with the script type of its
+// extension. Its includes are resolved relative to the snippet file. An
+// explicit --type must agree with the extension.
+func selectSnippet(path string) error {
+	scriptPath = path
+
+	snippetType := snippetTypes[strings.ToLower(filepath.Ext(path))]
+	if rootCmd.PersistentFlags().Changed("type") && !strings.EqualFold(scriptType, snippetType) {
+		return fmt.Errorf("snippet %s is a %s script, not %s", filepath.Base(path), snippetType, scriptType)
+	}
+	scriptType = snippetType
+	return nil
+}
+
+// bindSnippet binds the parameters of a groovy snippet as script variables
+// instead of substituting them. Javascript and beanshell snippets have no
+// variables and keep the ${name} substitution.
+func bindSnippet(params map[string]string) bool {
+	if scriptType != "groovy" {
+		return false
+	}
+	snippetParams = params
+	return true
+}
+
+// newGroovyExecutor creates an executor that displays results as requested
+// on the command line.
+func newGroovyExecutor(client *client.HACClient) *groovy.GroovyExecutor {
+	executor := groovy.NewGroovyExecutor(client)
+	executor.FullStacktrace = fullStacktrace
+	executor.Output = outputFormat
+	executor.ParseJSON = parseJSON
+	executor.Profile = conf.Profile
+	return executor
+}
+
+func defaultIncludePath() []string {
+	if value := os.Getenv("HACTOOLS_INCLUDE_PATH"); value != "" {
+		return filepath.SplitList(value)
+	}
+	return nil
+}
+
+// includeDirs searches the library last so snippets can share helpers.
+func includeDirs() []string {
+	return append(slices.Clone(includePath), conf.Library)
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/regioncache"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheOutput   string
+	cacheRegion   string
+	cacheWatch    bool
+	cacheInterval time.Duration
+)
+
+func init() {
+	cacheCmd.PersistentFlags().StringVarP(&cacheOutput, "output", "o", regioncache.OutputText, "Output format (text, json)")
+	cacheStatsCmd.Flags().BoolVarP(&cacheWatch, "watch", "w", false, "Refresh the statistics until interrupted")
+	cacheStatsCmd.Flags().DurationVar(&cacheInterval, "interval", 2*time.Second, "Time between refreshes when watching")
+	cacheClearCmd.Flags().StringVar(&cacheRegion, "region", "", "Only clear this region cache")
+
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Monitor and clear the region caches",
+	Long: `Reads and clears the region caches through HAC's Monitoring > Cache page.
+Statistics and clearing apply to the node that serves the request.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if cacheOutput != regioncache.OutputText && cacheOutput != regioncache.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", cacheOutput)
+		}
+		return nil
+	},
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show size, hits, misses and evictions of each region cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := newRegionCacheMonitor()
+		if err != nil {
+			return err
+		}
+		defer monitor.Client.Timings.Print(os.Stderr)
+
+		if cacheWatch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return monitor.Watch(ctx, cacheInterval)
+		}
+
+		regions, err := monitor.Stats()
+		if err != nil {
+			return err
+		}
+		return monitor.DisplayRegions(regions)
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear all region caches or a single one",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := "all region caches"
+		if cacheRegion != "" {
+			target = "region cache " + cacheRegion
+		}
+		if err := guard.Confirm(conf, "clear "+target, target); err != nil {
+			return err
+		}
+
+		monitor, err := newRegionCacheMonitor()
+		if err != nil {
+			return err
+		}
+		defer monitor.Client.Timings.Print(os.Stderr)
+
+		cleared, err := monitor.Clear(cacheRegion)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range cleared {
+			fmt.Printf("Cleared %s\n", name)
+		}
+		return nil
+	},
+}
+
+// newRegionCacheMonitor logs in for the cache subcommands. Callers print the
+// timings of the client when they are done.
+func newRegionCacheMonitor() (*regioncache.RegionCacheMonitor, error) {
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	monitor := regioncache.NewRegionCacheMonitor(client)
+	monitor.Output = cacheOutput
+	return monitor, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/cluster"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/spf13/cobra"
+)
+
+var clusterOutput string
+
+func init() {
+	clusterCmd.PersistentFlags().StringVarP(&clusterOutput, "output", "o", cluster.OutputText, "Output format (text, json)")
+
+	clusterCmd.AddCommand(clusterNodesCmd)
+}
+
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Inspect the cluster behind the load balancer",
+	Long: `Lists the cluster members as seen by the node that serves the request.
+Pin a node for any command with --node (or $HYBRIS_NODE), either by the
+value of its affinity cookie or by its HAC URL.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if clusterOutput != cluster.OutputText && clusterOutput != cluster.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", clusterOutput)
+		}
+		return nil
+	},
+}
+
+var clusterNodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "List the cluster nodes and their status",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+		client.PinNode(conf.Node)
+		if conf.Timings {
+			client.Timings = timing.New()
+			defer client.Timings.Print(os.Stderr)
+		}
+
+		if err := client.Login(); err != nil {
+			return fmt.Errorf("failed to login: %w", err)
+		}
+
+		manager := cluster.NewClusterManager(client)
+		manager.Output = clusterOutput
+
+		nodes, err := manager.Nodes()
+		if err != nil {
+			return err
+		}
+		return manager.DisplayNodes(nodes)
+	},
+}
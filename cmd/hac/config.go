@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/config"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/internal/properties"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configOutput string
+	diffLeft     string
+	diffRight    string
+	diffPrefix   string
+)
+
+func init() {
+	configCmd.PersistentFlags().StringVarP(&configOutput, "output", "o", properties.OutputText, "Output format (text, json)")
+	configDiffCmd.Flags().StringVar(&diffLeft, "left", "", "haccli profile of the left side (default: the current target)")
+	configDiffCmd.Flags().StringVar(&diffRight, "right", "", "haccli profile of the right side (default: the current target)")
+	configDiffCmd.Flags().StringVar(&diffPrefix, "prefix", "", "Only compare properties starting with this prefix")
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configDiffCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read, change and compare configuration properties",
+	Long: `Reads and changes properties through HAC's Platform > Configuration page.
+Changes only apply to the running configuration of the node that serves
+the request and are lost on restart.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if configOutput != properties.OutputText && configOutput != properties.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", configOutput)
+		}
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key|pattern>",
+	Short: "Show properties by key, glob (solr.*) or /regex/",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newPropertyManager(conf)
+		if err != nil {
+			return err
+		}
+		defer manager.Client.Timings.Print(os.Stderr)
+
+		values, err := manager.Get(args[0])
+		if err != nil {
+			return err
+		}
+		return manager.DisplayProperties(values)
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a property in the running configuration",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		if err := guard.Confirm(conf, "change a configuration property", key+"="+value); err != nil {
+			return err
+		}
+
+		manager, err := newPropertyManager(conf)
+		if err != nil {
+			return err
+		}
+		defer manager.Client.Timings.Print(os.Stderr)
+
+		previous, err := manager.Set(key, value)
+		if err != nil {
+			return err
+		}
+
+		if configOutput == properties.OutputJSON {
+			return manager.DisplayProperties(map[string]string{key: value})
+		}
+
+		if previous == nil {
+			fmt.Printf("%s: <unset> -> %s\n", key, value)
+		} else {
+			fmt.Printf("%s: %s -> %s\n", key, *previous, value)
+		}
+		return nil
+	},
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff --left <profile> --right <profile>",
+	Short: "Compare the properties of two haccli profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffLeft == "" && diffRight == "" {
+			return fmt.Errorf("at least one of --left and --right is required")
+		}
+
+		leftName, leftValues, err := readConfiguration(diffLeft)
+		if err != nil {
+			return err
+		}
+
+		rightName, rightValues, err := readConfiguration(diffRight)
+		if err != nil {
+			return err
+		}
+
+		manager := properties.NewPropertyManager(nil)
+		manager.Output = configOutput
+		return manager.DisplayDiff(properties.Diff(leftValues, rightValues, diffPrefix), leftName, rightName)
+	},
+}
+
+// readConfiguration reads all properties of a haccli profile, or of the
+// current target when profile is empty, and returns them with a name for
+// the target.
+func readConfiguration(profile string) (string, map[string]string, error) {
+	target := conf
+	if profile != "" {
+		var err error
+		target, err = config.LoadClient(profile, conf)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	manager, err := newPropertyManager(target)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", targetName(target), err)
+	}
+	defer manager.Client.Timings.Print(os.Stderr)
+
+	values, err := manager.Client.GetConfiguration()
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", targetName(target), err)
+	}
+	return targetName(target), values, nil
+}
+
+func targetName(target options.Config) string {
+	if target.Profile != "" {
+		return target.Profile
+	}
+	return target.Address
+}
+
+// newPropertyManager logs in to target. Callers print the timings of the
+// client when they are done.
+func newPropertyManager(target options.Config) (*properties.PropertyManager, error) {
+	client := client.NewHACClient(target.Address, target.User, target.Password)
+	client.PinNode(target.Node)
+	if target.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	manager := properties.NewPropertyManager(client)
+	manager.Output = configOutput
+	return manager, nil
+}
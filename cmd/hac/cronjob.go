@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/cronjob"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cronJobOutput   string
+	cronJobStatus   string
+	cronJobWait     bool
+	cronJobInterval time.Duration
+	cronJobTimeout  time.Duration
+)
+
+func init() {
+	cronJobCmd.PersistentFlags().StringVarP(&cronJobOutput, "output", "o", cronjob.OutputText, "Output format (text, json)")
+	cronJobListCmd.Flags().StringVar(&cronJobStatus, "status", "", "Only show cronjobs with this status (e.g. RUNNING)")
+	cronJobRunCmd.Flags().BoolVarP(&cronJobWait, "wait", "w", false, "Wait until the run finishes and fail if it does not succeed")
+	cronJobRunCmd.Flags().DurationVar(&cronJobInterval, "interval", 5*time.Second, "Time between polls when waiting")
+	cronJobRunCmd.Flags().DurationVar(&cronJobTimeout, "timeout", 0, "Give up waiting after this duration (0 waits forever)")
+
+	cronJobCmd.AddCommand(cronJobListCmd)
+	cronJobCmd.AddCommand(cronJobRunCmd)
+	cronJobCmd.AddCommand(cronJobAbortCmd)
+	cronJobCmd.AddCommand(cronJobLogsCmd)
+	cronJobCmd.AddCommand(cronJobHistoryCmd)
+}
+
+var cronJobCmd = &cobra.Command{
+	Use:   "cronjob",
+	Short: "List, run, abort and inspect CronJobs",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if cronJobOutput != cronjob.OutputText && cronJobOutput != cronjob.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", cronJobOutput)
+		}
+		return nil
+	},
+}
+
+var cronJobListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List CronJobs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newCronJobManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		jobs, err := manager.List(cronJobStatus)
+		if err != nil {
+			return err
+		}
+		return manager.DisplayJobs(jobs)
+	},
+}
+
+var cronJobRunCmd = &cobra.Command{
+	Use:   "run <code>",
+	Short: "Trigger a CronJob",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := guard.Confirm(conf, "run a cronjob", args[0]); err != nil {
+			return err
+		}
+
+		manager, err := newCronJobManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		previous, err := manager.Run(args[0])
+		if err != nil {
+			return err
+		}
+
+		if !cronJobWait {
+			if cronJobOutput == cronjob.OutputJSON {
+				return manager.DisplayJob(previous)
+			}
+			fmt.Printf("Triggered cronjob %s\n", previous.Code)
+			return nil
+		}
+
+		if cronJobOutput == cronjob.OutputText {
+			fmt.Fprintf(os.Stderr, "Triggered cronjob %s, waiting for it to finish...\n", previous.Code)
+		}
+
+		job, err := manager.Wait(previous, cronJobInterval, cronJobTimeout)
+		if err != nil {
+			return err
+		}
+
+		if err := manager.DisplayJob(job); err != nil {
+			return err
+		}
+
+		if cronjob.Failed(job) {
+			return fmt.Errorf("cronjob %s finished with status %s and result %s", job.Code, job.Status, job.Result)
+		}
+		return nil
+	},
+}
+
+var cronJobAbortCmd = &cobra.Command{
+	Use:   "abort <code>",
+	Short: "Request a running CronJob to abort",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := guard.Confirm(conf, "abort a cronjob", args[0]); err != nil {
+			return err
+		}
+
+		manager, err := newCronJobManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		job, err := manager.Abort(args[0])
+		if err != nil {
+			return err
+		}
+
+		if cronJobOutput == cronjob.OutputJSON {
+			return manager.DisplayJob(job)
+		}
+		fmt.Printf("Requested abort of cronjob %s\n", job.Code)
+		return nil
+	},
+}
+
+var cronJobLogsCmd = &cobra.Command{
+	Use:   "logs <code>",
+	Short: "Print the most recent log of a CronJob",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newCronJobManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		logs, err := manager.Logs(args[0])
+		if err != nil {
+			return err
+		}
+		return manager.DisplayLogs(logs)
+	},
+}
+
+var cronJobHistoryCmd = &cobra.Command{
+	Use:   "history <code>",
+	Short: "Show the past runs of a CronJob",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newCronJobManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		runs, err := manager.History(args[0])
+		if err != nil {
+			return err
+		}
+		return manager.DisplayHistory(runs)
+	},
+}
+
+// newCronJobManager logs in for the cronjob subcommands. Callers print the
+// timings of the client when they are done.
+func newCronJobManager() (*cronjob.CronJobManager, error) {
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	manager := cronjob.NewCronJobManager(client)
+	manager.Output = cronJobOutput
+	return manager, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/diagnostics"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diagOutput   string
+	diagCount    int
+	diagInterval time.Duration
+	diagDir      string
+	diagDepth    int
+	diagTop      int
+)
+
+func init() {
+	diagCmd.PersistentFlags().StringVarP(&diagOutput, "output", "o", diagnostics.OutputText, "Output format (text, json)")
+	for _, cmd := range []*cobra.Command{diagThreadsCmd, diagSummaryCmd} {
+		cmd.Flags().IntVar(&diagDepth, "depth", 10, "Number of top frames that make threads share a stack")
+		cmd.Flags().IntVar(&diagTop, "top", 10, "Number of pools and stacks to show, 0 for all")
+	}
+	diagThreadsCmd.Flags().IntVarP(&diagCount, "count", "n", 1, "Number of thread dumps to take")
+	diagThreadsCmd.Flags().DurationVar(&diagInterval, "interval", 5*time.Second, "Time between thread dumps")
+	diagThreadsCmd.Flags().StringVarP(&diagDir, "dir", "d", ".", "Directory to write the thread dumps to")
+
+	diagCmd.AddCommand(diagThreadsCmd)
+	diagCmd.AddCommand(diagMemoryCmd)
+	diagCmd.AddCommand(diagSummaryCmd)
+}
+
+var diagCmd = &cobra.Command{
+	Use:   "diag",
+	Short: "Capture thread dumps and memory figures",
+	Long: `Captures thread dumps and memory figures of the node that serves the
+request, through the JVM management beans. Pin a node with --node to
+diagnose a specific one.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if diagOutput != diagnostics.OutputText && diagOutput != diagnostics.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", diagOutput)
+		}
+		return nil
+	},
+}
+
+var diagThreadsCmd = &cobra.Command{
+	Use:   "threads",
+	Short: "Download thread dumps to timestamped files and summarize them",
+	Long: `Takes --count thread dumps, --interval apart, writes each one to a
+timestamped file in the jstack format and summarizes the last one. With
+several dumps, the summary counts the threads whose stack did not change
+between them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newDiagnosticsManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		dumps, err := manager.Capture(diagCount, diagInterval, diagDir, func(path string, dump *models.ThreadDump) {
+			fmt.Fprintf(os.Stderr, "Wrote %s (%d threads)\n", path, len(dump.Threads))
+		})
+		if err != nil {
+			return err
+		}
+		if diagOutput == diagnostics.OutputText {
+			fmt.Fprintln(os.Stderr)
+		}
+
+		return manager.DisplaySummary(diagnostics.Summarize(dumps, diagDepth), len(dumps), diagTop)
+	},
+}
+
+var diagSummaryCmd = &cobra.Command{
+	Use:   "summary <file>...",
+	Short: "Summarize thread dump files taken by diag threads or jstack",
+	Long: `Groups the threads of the last file by pool, state and stack. With
+several files of the same node, in the order they were taken, the summary
+counts the threads whose stack did not change between them.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dumps := make([]models.ThreadDump, 0, len(args))
+		for _, path := range args {
+			dump, err := diagnostics.ReadThreadDump(path)
+			if err != nil {
+				return err
+			}
+			dumps = append(dumps, *dump)
+		}
+
+		manager := &diagnostics.DiagnosticsManager{Output: diagOutput}
+		return manager.DisplaySummary(diagnostics.Summarize(dumps, diagDepth), len(dumps), diagTop)
+	},
+}
+
+var diagMemoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Show heap, non-heap, memory pool and garbage collection figures",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newDiagnosticsManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Groovy.Client.Timings.Print(os.Stderr)
+
+		memory, err := manager.Memory()
+		if err != nil {
+			return err
+		}
+		return manager.DisplayMemory(memory)
+	},
+}
+
+// newDiagnosticsManager logs in for the diag subcommands. Callers print the
+// timings of the client when they are done.
+func newDiagnosticsManager() (*diagnostics.DiagnosticsManager, error) {
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	manager := diagnostics.NewDiagnosticsManager(client)
+	manager.Output = diagOutput
+	return manager, nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/cluster"
+	"github.com/Salvadego/HacTools/internal/config"
+	"github.com/Salvadego/HacTools/internal/flexsearch"
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/history"
+	"github.com/Salvadego/HacTools/internal/impex"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+var historyFilter models.HistoryFilter
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyFilter.Tool, "tool", "t", "", "Only show executions of this tool (xf, xg, ii)")
+	historyCmd.Flags().StringVar(&historyFilter.Profile, "profile", "", "Only show executions against this profile")
+	historyCmd.Flags().StringVarP(&historyFilter.Pattern, "grep", "g", "", "Only show executions whose payload or address matches this regex")
+	historyCmd.Flags().IntVarP(&historyFilter.Limit, "limit", "n", 20, "Maximum number of entries to show (0 for all)")
+
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyRerunCmd)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Search the log of past xf, xg and ii executions",
+	Long: `Every xf, xg and ii execution is appended to a local history file
+(~/.config/hactools/history.jsonl or $HACTOOLS_HISTORY). Passwords are
+redacted before they are written. Set $HACTOOLS_NO_HISTORY=1 or pass
+--no-history to opt out.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := history.Search(historyFilter)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No history entries found")
+			return nil
+		}
+
+		table := output.NewTable([]string{"ID", "Time", "Tool", "Profile", "Duration", "Outcome", "Payload"})
+
+		for _, entry := range entries {
+			table.Append([]string{
+				strconv.Itoa(entry.ID),
+				entry.Timestamp.Local().Format("2006-01-02 15:04:05"),
+				entry.Tool,
+				profileOrAddress(entry),
+				(time.Duration(entry.DurationMs) * time.Millisecond).String(),
+				truncate(entry.Outcome, 30),
+				truncate(entry.Payload, 60),
+			})
+		}
+
+		table.Render()
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a history entry in full",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := getHistoryEntry(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("ID:         %d\n", entry.ID)
+		fmt.Printf("Time:       %s\n", entry.Timestamp.Local().Format(time.RFC3339))
+		fmt.Printf("Tool:       %s\n", entry.Tool)
+		fmt.Printf("Profile:    %s\n", entry.Profile)
+		fmt.Printf("Address:    %s\n", entry.Address)
+		fmt.Printf("User:       %s\n", entry.User)
+		fmt.Printf("Duration:   %s\n", time.Duration(entry.DurationMs)*time.Millisecond)
+		fmt.Printf("Rows:       %d\n", entry.Rows)
+		fmt.Printf("Outcome:    %s\n", entry.Outcome)
+		fmt.Printf("Exit:       %d\n", entry.ExitStatus)
+		if entry.PayloadHash != "" {
+			fmt.Printf("Hash:       %s\n", entry.PayloadHash)
+		} else {
+			fmt.Printf("Hash:       (redacted)\n")
+		}
+		keys := make([]string, 0, len(entry.Options))
+		for key := range entry.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("Option:     %s=%s\n", key, entry.Options[key])
+		}
+
+		fmt.Println("\n=== PAYLOAD ===")
+		fmt.Println(entry.Payload)
+		return nil
+	},
+}
+
+var historyRerunCmd = &cobra.Command{
+	Use:   "rerun <id>",
+	Short: "Execute a history entry again against the current profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entry, err := getHistoryEntry(args[0])
+		if err != nil {
+			return err
+		}
+
+		if history.Hash(entry.Payload) != entry.PayloadHash {
+			return fmt.Errorf("history entry %d was redacted and cannot be replayed", entry.ID)
+		}
+
+		return rerunEntry(entry)
+	},
+}
+
+// rerunOnAllNodes runs a script entry of xg --all-nodes again on every node
+// of the cluster, starting from the session of first.
+func rerunOnAllNodes(first *client.HACClient, script string, opts models.GroovyExecuteOptions, entry *models.HistoryEntry) error {
+	fileConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	timings := first.Timings
+	login := func(pin string) (*client.HACClient, error) {
+		if first != nil && pin == "" {
+			c := first
+			first = nil
+			return c, nil
+		}
+
+		c := client.NewHACClient(conf.Address, conf.User, conf.Password)
+		c.PinNode(pin)
+		c.Timings = timings
+		if err := c.Login(); err != nil {
+			return nil, fmt.Errorf("failed to login: %w", err)
+		}
+		return c, nil
+	}
+
+	sessions, missing, err := cluster.Sessions(login, fileConfig.NodesFor(conf.Profile))
+	if err != nil {
+		return err
+	}
+
+	results := cluster.Execute(sessions, missing, func(c *client.HACClient) (*models.GroovyResponse, error) {
+		return groovy.NewGroovyExecutor(c).Execute(script, opts)
+	})
+
+	entry.Outcome = fmt.Sprintf("%d nodes", len(results))
+	return groovy.NewGroovyExecutor(nil).DisplayNodeResults(results)
+}
+
+func getHistoryEntry(arg string) (*models.HistoryEntry, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history id: %s", arg)
+	}
+	return history.Get(id)
+}
+
+func rerunEntry(original *models.HistoryEntry) (err error) {
+	start := time.Now()
+	entry := models.HistoryEntry{
+		Tool:    original.Tool,
+		Payload: original.Payload,
+		Options: map[string]string{"rerunOf": strconv.Itoa(original.ID)},
+	}
+	for key, value := range original.Options {
+		entry.Options[key] = value
+	}
+	defer func() { history.Record(conf, &entry, start, err) }()
+
+	if optionBool(original, "allNodes") && conf.Node != "" {
+		return fmt.Errorf("history entry %d ran on all nodes and cannot be rerun with --node", original.ID)
+	}
+
+	if action := mutatingAction(original); action != "" {
+		if err := guard.Confirm(conf, action, original.Payload); err != nil {
+			return err
+		}
+	}
+
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+		defer client.Timings.Print(os.Stderr)
+	}
+
+	if err := client.Login(); err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	switch original.Tool {
+	case "xf":
+		fileConfig, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		maxCount, _ := strconv.Atoi(original.Options["maxCount"])
+		columns := flexsearch.ResolveColumns(fileConfig.ColumnsFor(conf.Profile),
+			history.DecodeList(original.Options["columns"]), history.DecodeList(original.Options["exclude"]), optionBool(original, "noBlacklist"))
+
+		executor := flexsearch.NewFlexSearchExecutor(client)
+		result, err := executor.Execute(original.Payload, models.FlexExecuteOptions{
+			MaxCount:  maxCount,
+			NoAnalyze: optionBool(original, "noAnalyze"),
+			Expand:    optionBool(original, "expand"),
+			Columns:   columns,
+			KeepEmpty: optionBool(original, "keepEmpty"),
+			Locale:    original.Options["locale"],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+
+		err = flexsearch.Transform(result, models.FlexTransformOptions{
+			Where:   optionLines(original, "where"),
+			GroupBy: optionList(original, "groupBy"),
+			Agg:     optionList(original, "agg"),
+			Sort:    optionList(original, "sort"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to transform results: %w", err)
+		}
+
+		entry.Rows = len(result.ResultList)
+		entry.Outcome = fmt.Sprintf("%d rows", entry.Rows)
+		return executor.DisplayResults(result)
+	case "xg":
+		vars, err := optionVars(original, "vars")
+		if err != nil {
+			return err
+		}
+
+		opts := models.GroovyExecuteOptions{
+			ScriptType:  original.Options["scriptType"],
+			Commit:      optionBool(original, "commit"),
+			Vars:        vars,
+			Path:        original.Options["path"],
+			IncludePath: filepath.SplitList(original.Options["includePath"]),
+			DryRun:      optionBool(original, "dryRun"),
+		}
+
+		executor := groovy.NewGroovyExecutor(client)
+		executor.Profile = conf.Profile
+
+		switch {
+		case optionBool(original, "allNodes"):
+			return rerunOnAllNodes(client, original.Payload, opts, &entry)
+		case optionBool(original, "async"):
+			job, err := executor.Submit(original.Payload, opts)
+			if err != nil {
+				return fmt.Errorf("failed to submit script: %w", err)
+			}
+			entry.Outcome = "submitted job " + job.ID
+			fmt.Printf("Submitted job %s, follow it with: xg wait %s\n", job.ID, job.ID)
+			return nil
+		}
+
+		result, err := executor.Execute(original.Payload, opts)
+		if err != nil {
+			return fmt.Errorf("failed to execute script: %w", err)
+		}
+		return executor.DisplayResults(result)
+	case "ii":
+		importer := impex.NewImpexImporter(client)
+		result, err := importer.ImportScript(original.Payload, models.ImpexExecuteOptions{
+			LegacyMode:          optionBool(original, "legacyMode"),
+			EnableCodeExecution: optionBool(original, "enableCodeExecution"),
+			DistributedMode:     optionBool(original, "distributedMode"),
+			SldEnabled:          optionBool(original, "sldEnabled"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to execute script: %w", err)
+		}
+		return importer.DisplayResults(result)
+	default:
+		return fmt.Errorf("cannot rerun entries of tool %q", original.Tool)
+	}
+}
+
+// mutatingAction describes what rerunning entry changes on the target, or
+// returns an empty string for read-only executions.
+func mutatingAction(entry *models.HistoryEntry) string {
+	switch {
+	case entry.Tool == "ii":
+		return "import impex"
+	case entry.Tool == "xg" && optionBool(entry, "commit"):
+		return "run a script with commit"
+	default:
+		return ""
+	}
+}
+
+func optionBool(entry *models.HistoryEntry, key string) bool {
+	value, _ := strconv.ParseBool(entry.Options[key])
+	return value
+}
+
+func optionList(entry *models.HistoryEntry, key string) []string {
+	if entry.Options[key] == "" {
+		return nil
+	}
+	return strings.Split(entry.Options[key], ",")
+}
+
+func optionLines(entry *models.HistoryEntry, key string) []string {
+	if entry.Options[key] == "" {
+		return nil
+	}
+	return strings.Split(entry.Options[key], "\n")
+}
+
+func optionVars(entry *models.HistoryEntry, key string) (map[string]any, error) {
+	if entry.Options[key] == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(entry.Options[key]))
+	decoder.UseNumber()
+
+	var vars map[string]any
+	if err := decoder.Decode(&vars); err != nil {
+		return nil, fmt.Errorf("failed to decode stored variables: %w", err)
+	}
+	return vars, nil
+}
+
+func profileOrAddress(entry models.HistoryEntry) string {
+	if entry.Profile != "" {
+		return entry.Profile
+	}
+	return entry.Address
+}
+
+func truncate(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-1] + "…"
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/loglevel"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevelOutput string
+	logLevelFor    time.Duration
+)
+
+func init() {
+	logLevelCmd.PersistentFlags().StringVarP(&logLevelOutput, "output", "o", loglevel.OutputText, "Output format (text, json)")
+	logLevelSetCmd.Flags().DurationVar(&logLevelFor, "for", 0, "Restore the previous level after this duration or on Ctrl-C")
+
+	logLevelCmd.AddCommand(logLevelGetCmd)
+	logLevelCmd.AddCommand(logLevelSetCmd)
+}
+
+var logLevelCmd = &cobra.Command{
+	Use:   "log-level",
+	Short: "Read and change logger levels at runtime",
+	Long: `Reads and changes logger levels through HAC's Platform > Logging page.
+Changes only apply to the node that serves the request and are lost on
+restart.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		rootCmd.PersistentPreRun(cmd, args)
+		if logLevelOutput != loglevel.OutputText && logLevelOutput != loglevel.OutputJSON {
+			return fmt.Errorf("invalid output format: %s (must be text or json)", logLevelOutput)
+		}
+		return nil
+	},
+}
+
+var logLevelGetCmd = &cobra.Command{
+	Use:   "get <logger>",
+	Short: "Show the effective level of a logger",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager, err := newLogLevelManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Client.Timings.Print(os.Stderr)
+
+		level, err := manager.Get(args[0])
+		if err != nil {
+			return err
+		}
+		return manager.DisplayLevel(level)
+	},
+}
+
+var logLevelSetCmd = &cobra.Command{
+	Use:   "set <logger> <level>",
+	Short: "Change the level of a logger, optionally for a limited time",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, level := args[0], args[1]
+		if err := guard.Confirm(conf, "change a log level", name+"="+level); err != nil {
+			return err
+		}
+
+		manager, err := newLogLevelManager()
+		if err != nil {
+			return err
+		}
+		defer manager.Client.Timings.Print(os.Stderr)
+
+		// Ctrl-C while the level is changed restores it instead of leaving it.
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		previous, err := manager.Set(name, level)
+		if err != nil {
+			return err
+		}
+
+		level = strings.ToUpper(level)
+		// JSON output is one document, printed once a timed change is
+		// restored.
+		change := &models.LoggerLevelChange{Changed: models.LoggerLevel{Name: name, Level: level}, Previous: *previous}
+		if logLevelOutput != loglevel.OutputJSON {
+			fmt.Printf("%s: %s -> %s\n", name, previous.Level, level)
+		}
+
+		if logLevelFor == 0 {
+			if logLevelOutput == loglevel.OutputJSON {
+				return manager.DisplayChange(change)
+			}
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Restoring %s to %s at %s (Ctrl-C to restore now)\n",
+			name, previous.Level, time.Now().Add(logLevelFor).Format("15:04:05"))
+
+		timer := time.NewTimer(logLevelFor)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr)
+		}
+
+		if err := manager.Restore(previous); err != nil {
+			if logLevelOutput == loglevel.OutputJSON {
+				manager.DisplayChange(change)
+			}
+			return err
+		}
+
+		if logLevelOutput == loglevel.OutputJSON {
+			change.Restored = &models.LoggerLevel{Name: name, Level: previous.Level}
+			return manager.DisplayChange(change)
+		}
+		fmt.Printf("%s: %s -> %s\n", name, level, previous.Level)
+		return nil
+	},
+}
+
+// newLogLevelManager logs in for the log-level subcommands. Callers print
+// the timings of the client when they are done.
+func newLogLevelManager() (*loglevel.LogLevelManager, error) {
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+	}
+
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	manager := loglevel.NewLogLevelManager(client)
+	manager.Output = logLevelOutput
+	return manager, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/spf13/cobra"
+)
+
+var logLevel string
+
+var conf options.Config
+
+func init() {
+	options.GetDefaults(rootCmd, &conf)
+	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "error", "Log level (debug, info, error, none)")
+
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(cronJobCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(logLevelCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(clusterCmd)
+	rootCmd.AddCommand(diagCmd)
+	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(initializeCmd)
+}
+
+var rootCmd = &cobra.Command{
+	Use:          "hac",
+	Short:        "Manage Hybris HAC and the HacTools workspace",
+	SilenceUsage: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		logger.SetLogLevel(logger.LogLevelFromString(logLevel))
+	},
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
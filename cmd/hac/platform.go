@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/platform"
+	"github.com/Salvadego/HacTools/internal/timing"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	platformOptions models.InitUpdateOptions
+	platformDryRun  bool
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{updateCmd, initializeCmd} {
+		cmd.Flags().BoolVar(&platformOptions.Essential, "essential", false, "Create essential data")
+		cmd.Flags().StringSliceVar(&platformOptions.ProjectData, "project-data", nil, "Import the project data of these extensions (comma separated)")
+		cmd.Flags().StringArrayVar(&platformOptions.Parameters, "param", nil, "Project data option as extension_parameter=value (repeatable)")
+		cmd.Flags().BoolVar(&platformOptions.ClearHMC, "clear-hmc", false, "Clear the HMC configuration from the database")
+		cmd.Flags().BoolVar(&platformOptions.LocalizeTypes, "localize-types", false, "Localize the types")
+		cmd.Flags().BoolVar(&platformDryRun, "dry-run", false, "Print the request instead of sending it")
+	}
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the running system and stream its log",
+	Long: `Runs HAC's Platform > Update with the selected essential and project
+data and prints the log as the server writes it. Exits non-zero when the
+update fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInitUpdate(platform.MethodUpdate, "update the running system")
+	},
+}
+
+var initializeCmd = &cobra.Command{
+	Use:   "initialize",
+	Short: "Initialize the platform, dropping all data, and stream its log",
+	Long: `Runs HAC's Platform > Initialization, which drops and recreates all
+tables, and prints the log as the server writes it. Exits non-zero when the
+initialization fails. It always asks to type the host name; on protected
+environments --yes-i-mean-prod skips the question.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInitUpdate(platform.MethodInit, "initialize the platform and drop all data")
+	},
+}
+
+// runInitUpdate sends an update or initialization after the guard confirmed
+// action, or prints the request on dry runs. Initializations are confirmed
+// on every environment.
+func runInitUpdate(method, action string) error {
+	request, err := platform.NewRequest(method, platformOptions)
+	if err != nil {
+		return err
+	}
+
+	if platformDryRun {
+		return platform.DisplayRequest(request)
+	}
+
+	payload, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	confirm := guard.Confirm
+	if method == platform.MethodInit {
+		confirm = guard.ConfirmAlways
+	}
+	if err := confirm(conf, action, string(payload)); err != nil {
+		return err
+	}
+
+	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+		defer client.Timings.Print(os.Stderr)
+	}
+
+	if err := client.Login(); err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	start := time.Now()
+	fmt.Fprintf(os.Stderr, "Starting %s on %s\n", platform.Name(method), conf.Address)
+	if _, err := platform.NewPlatformManager(client).Run(request, os.Stdout); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Finished %s in %s\n", platform.Name(method), time.Since(start).Round(time.Second))
+	return nil
+}
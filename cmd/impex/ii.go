@@ -3,12 +3,18 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/client"
 	"github.com/Salvadego/HacTools/internal/editor"
+	"github.com/Salvadego/HacTools/internal/guard"
+	"github.com/Salvadego/HacTools/internal/history"
 	"github.com/Salvadego/HacTools/internal/impex"
+	"github.com/Salvadego/HacTools/internal/library"
 	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/internal/timing"
 	"github.com/Salvadego/HacTools/models"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +29,12 @@ var (
 
 var conf options.Config
 
+var libraryConfig = models.LibraryConfig{
+	Dir:          &conf.Library,
+	Extensions:   []string{".impex"},
+	ExecutorFunc: executorFunc,
+}
+
 func init() {
 	options.GetDefaults(rootCmd, &conf)
 	rootCmd.PersistentFlags().BoolVarP(&legacyMode, "legacy", "L", false, "Enable legacyMode")
@@ -39,54 +51,78 @@ func init() {
 	})
 
 	rootCmd.AddCommand(editorCommand)
+	rootCmd.AddCommand(library.CreateLibraryCommands(libraryConfig)...)
 }
 
 var rootCmd = &cobra.Command{
-	Use:          "ii [script or file path]",
+	Use:          "ii [script, snippet name or file path]",
 	Short:        "Import Impex against Hybris HAC",
 	Long:         `A impex importer for Hybris HAC`,
 	Args:         cobra.ExactArgs(1),
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger.SetLogLevel(logger.LogLevelFromString(logLevel))
-		client := client.NewHACClient(conf.Address, conf.User, conf.Password)
-		if err := client.Login(); err != nil {
-			return fmt.Errorf("failed to login: %w", err)
-		}
-
 		arg := args[0]
 
-		importer := impex.NewImpexImporter(client)
-		options := models.ImpexExecuteOptions{
-			LegacyMode:          legacyMode,
-			EnableCodeExecution: enableCodeExecution,
-			DistributedMode:     distributedMode,
-			SldEnabled:          sldEnabled,
+		content, found, err := library.Resolve(libraryConfig, arg)
+		if err != nil {
+			return fmt.Errorf("failed to resolve snippet: %w", err)
 		}
 
-		if _, err := os.Stat(arg); err == nil {
-			result, err := importer.ImportFile(arg, options)
-
-			if err != nil {
-				return fmt.Errorf("failed to execute script: %w", err)
-			}
-
-			return importer.DisplayResults(result)
+		if found {
+			return executorFunc(content)
 		}
 
-		var script string
-		script = arg
-		result, err := importer.ImportScript(script, options)
-		if err != nil {
-			return fmt.Errorf("failed to execute script: %w", err)
+		if _, err := os.Stat(arg); err == nil {
+			return fileExecutorFunc(arg)
 		}
 
-		return importer.DisplayResults(result)
+		return executorFunc(arg)
 	},
 }
 
 func executorFunc(script string) error {
+	return runImport(script, func(importer *impex.ImpexImporter, options models.ImpexExecuteOptions) (string, error) {
+		return importer.ImportScript(script, options)
+	})
+}
+
+func fileExecutorFunc(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read script file: %w", err)
+	}
+
+	return runImport(string(data), func(importer *impex.ImpexImporter, options models.ImpexExecuteOptions) (string, error) {
+		return importer.ImportFile(path, options)
+	})
+}
+
+func runImport(payload string, importFunc func(*impex.ImpexImporter, models.ImpexExecuteOptions) (string, error)) (err error) {
+	start := time.Now()
+	entry := models.HistoryEntry{
+		Tool:    "ii",
+		Payload: payload,
+		Options: map[string]string{
+			"legacyMode":          strconv.FormatBool(legacyMode),
+			"enableCodeExecution": strconv.FormatBool(enableCodeExecution),
+			"distributedMode":     strconv.FormatBool(distributedMode),
+			"sldEnabled":          strconv.FormatBool(sldEnabled),
+		},
+	}
+	defer func() { history.Record(conf, &entry, start, err) }()
+
+	if err := guard.Confirm(conf, "import impex", payload); err != nil {
+		return err
+	}
+
 	client := client.NewHACClient(conf.Address, conf.User, conf.Password)
+	client.PinNode(conf.Node)
+	if conf.Timings {
+		client.Timings = timing.New()
+		defer client.Timings.Print(os.Stderr)
+	}
+
 	if err := client.Login(); err != nil {
 		return fmt.Errorf("failed to login: %w", err)
 	}
@@ -99,7 +135,7 @@ func executorFunc(script string) error {
 		SldEnabled:          sldEnabled,
 	}
 
-	result, err := importer.ImportScript(script, options)
+	result, err := importFunc(importer, options)
 	if err != nil {
 		return fmt.Errorf("failed to execute script: %w", err)
 	}
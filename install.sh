@@ -19,7 +19,7 @@ HACTOOLS_REPO="github.com/Salvadego/HacTools"
 HACTOOLS_VERSION="latest"
 HACTOOLS_INSTALL_DIR="${HACTOOLS_INSTALL_DIR:-$HOME/.config/hactools}"
 HACTOOLS_BIN_DIR="${HACTOOLS_INSTALL_DIR}/bin"
-HACTOOLS_CMDS=("xf" "xg" "ii")
+HACTOOLS_CMDS=("xf" "xg" "ii" "hac")
 NO_UPDATE=0
 
 function print_help() {
@@ -105,7 +105,7 @@ trap 'rm -rf $temp_dir' EXIT
 cd "$temp_dir"
 
 echo "Downloading hactools..."
-if ! go install "$HACTOOLS_REPO/cmd/flex@$HACTOOLS_VERSION" "$HACTOOLS_REPO/cmd/groovy@$HACTOOLS_VERSION" "$HACTOOLS_REPO/cmd/impex@$HACTOOLS_VERSION"; then
+if ! go install "$HACTOOLS_REPO/cmd/flex@$HACTOOLS_VERSION" "$HACTOOLS_REPO/cmd/groovy@$HACTOOLS_VERSION" "$HACTOOLS_REPO/cmd/impex@$HACTOOLS_VERSION" "$HACTOOLS_REPO/cmd/hac@$HACTOOLS_VERSION"; then
   echo "Error: Failed to download and install hactools."
   exit 1
 fi
@@ -117,6 +117,7 @@ for cmd in "${HACTOOLS_CMDS[@]}"; do
     xf) src_cmd="flex" ;;
     xg) src_cmd="groovy" ;;
     ii) src_cmd="impex" ;;
+    hac) src_cmd="hac" ;;
   esac
 
   if [ -f "$GOPATH/bin/$src_cmd" ]; then
@@ -181,4 +182,5 @@ echo "For help, run:"
 echo "  xf --help"
 echo "  xg --help"
 echo "  ii --help"
+echo "  hac --help"
 echo "  haccli --help"
@@ -0,0 +1,220 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/filelock"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const statsFile = "stats.json"
+
+type Cache struct {
+	Dir string
+}
+
+type entry struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Value     json.RawMessage `json:"value"`
+}
+
+func New(name string) *Cache {
+	dir, exists := os.LookupEnv("HACTOOLS_CACHE_DIR")
+	if !exists || dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "hactools")
+	}
+
+	return &Cache{Dir: filepath.Join(dir, name)}
+}
+
+// Key hashes the given parts into a cache key.
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get decodes the entry stored under key into target when it is younger than
+// ttl.
+func (c *Cache) Get(key string, ttl time.Duration, target any) bool {
+	hit := c.get(key, ttl, target)
+	c.updateStats(func(stats *models.CacheStats) {
+		if hit {
+			stats.Hits++
+		} else {
+			stats.Misses++
+		}
+	})
+	return hit
+}
+
+func (c *Cache) get(key string, ttl time.Duration, target any) bool {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		logger.Debug("Ignoring corrupt cache entry %s: %v", key, err)
+		return false
+	}
+
+	if time.Since(e.CreatedAt) > ttl {
+		logger.Debug("Cache entry %s expired", key)
+		return false
+	}
+
+	if err := json.Unmarshal(e.Value, target); err != nil {
+		logger.Debug("Ignoring undecodable cache entry %s: %v", key, err)
+		return false
+	}
+
+	logger.Info("Using cached result from %s", e.CreatedAt.Format(time.RFC3339))
+	return true
+}
+
+func (c *Cache) Put(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Value: raw})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, c.path(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	c.updateStats(func(stats *models.CacheStats) { stats.Writes++ })
+	return nil
+}
+
+// Clear removes every entry and resets the statistics. It returns the number
+// of removed entries.
+func (c *Cache) Clear() (int, error) {
+	files, err := c.entryFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return 0, fmt.Errorf("failed to remove cache entry: %w", err)
+		}
+	}
+
+	path := filepath.Join(c.Dir, statsFile)
+	release, err := filelock.Acquire(path)
+	if err != nil {
+		return len(files), err
+	}
+	defer release()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return len(files), fmt.Errorf("failed to reset cache statistics: %w", err)
+	}
+
+	return len(files), nil
+}
+
+func (c *Cache) Stats() (models.CacheStats, error) {
+	stats := c.readStats()
+
+	files, err := c.entryFiles()
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Entries = len(files)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stats.Bytes += info.Size()
+		}
+	}
+
+	return stats, nil
+}
+
+func (c *Cache) entryFiles() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+
+	entries := files[:0]
+	for _, file := range files {
+		if filepath.Base(file) != statsFile {
+			entries = append(entries, file)
+		}
+	}
+	return entries, nil
+}
+
+func (c *Cache) readStats() models.CacheStats {
+	var stats models.CacheStats
+	if data, err := os.ReadFile(filepath.Join(c.Dir, statsFile)); err == nil {
+		json.Unmarshal(data, &stats)
+	}
+	return stats
+}
+
+// updateStats applies update to the statistics under a lock, so that
+// concurrent runs do not lose each other's counts.
+func (c *Cache) updateStats(update func(*models.CacheStats)) {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		logger.Debug("Failed to create cache directory: %v", err)
+		return
+	}
+
+	path := filepath.Join(c.Dir, statsFile)
+	release, err := filelock.Acquire(path)
+	if err != nil {
+		logger.Debug("Failed to update cache statistics: %v", err)
+		return
+	}
+	defer release()
+
+	stats := c.readStats()
+	update(&stats)
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		logger.Debug("Failed to write cache statistics: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		logger.Debug("Failed to write cache statistics: %v", err)
+	}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+
+	var value []string
+	if c.Get("k", time.Minute, &value) {
+		t.Fatal("Get() hit on an empty cache")
+	}
+
+	if err := c.Put("k", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get("k", time.Minute, &value) || len(value) != 2 {
+		t.Fatalf("Get() = %v, want the stored value", value)
+	}
+	if c.Get("k", 0, &value) {
+		t.Error("Get() hit an entry older than the ttl")
+	}
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Writes != 1 || stats.Entries != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit, 2 misses, 1 write and 1 entry", stats)
+	}
+
+	removed, err := c.Clear()
+	if err != nil || removed != 1 {
+		t.Fatalf("Clear() = %d, %v, want 1 entry removed", removed, err)
+	}
+	if stats, _ := c.Stats(); stats.Hits != 0 || stats.Entries != 0 {
+		t.Errorf("Stats() after Clear() = %+v, want zero", stats)
+	}
+}
+
+func TestConcurrentStats(t *testing.T) {
+	c := &Cache{Dir: t.TempDir()}
+
+	const count = 50
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var value string
+			c.Get("missing", time.Minute, &value)
+		}()
+	}
+	wg.Wait()
+
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Misses != count {
+		t.Errorf("Misses = %d, want %d", stats.Misses, count)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("Key() does not separate its parts")
+	}
+	if Key("a", "b") != Key("a", "b") {
+		t.Error("Key() is not stable")
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/timing"
 )
 
 type HACClient struct {
@@ -20,6 +22,10 @@ type HACClient struct {
 	Username string
 	Password string
 	Csrf     string
+	Timings  *timing.Timings
+
+	// route is the affinity cookie set by PinNode, kept across logins.
+	route *http.Cookie
 }
 
 func NewHACClient(baseURL, username, password string) *HACClient {
@@ -50,6 +56,7 @@ func (c *HACClient) clearSession() {
 	logger.Debug("Clearing session and CSRF token")
 	c.Client.Jar, _ = cookiejar.New(nil)
 	c.Csrf = ""
+	c.setRoute()
 }
 
 func (c *HACClient) extractCSRFToken(body string) (string, error) {
@@ -92,6 +99,7 @@ func (c *HACClient) getInitialCSRFToken() (string, error) {
 }
 
 func (c *HACClient) Login() error {
+	defer c.Timings.Track("login", time.Now())
 	logger.Info("Starting login process")
 	c.clearSession()
 
@@ -149,7 +157,17 @@ func (c *HACClient) Login() error {
 	return nil
 }
 
+// EnsureLoggedIn logs in unless the client already holds a session.
+func (c *HACClient) EnsureLoggedIn() error {
+	if c.Csrf != "" {
+		return nil
+	}
+	return c.Login()
+}
+
 func (c *HACClient) Post(endpoint string, data url.Values) ([]byte, error) {
+	defer c.Timings.Track("request "+endpoint, time.Now())
+
 	resp, err := c.Client.PostForm(c.BaseURL+endpoint, data)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -176,7 +194,30 @@ func (c *HACClient) Post(endpoint string, data url.Values) ([]byte, error) {
 	return body, nil
 }
 
+func (c *HACClient) Get(endpoint string) ([]byte, error) {
+	defer c.Timings.Track("request "+endpoint, time.Now())
+
+	resp, err := c.Client.Get(c.BaseURL + endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("received HTTP %d error: %s", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
 func (c *HACClient) PostMultipart(endpoint string, body *bytes.Buffer, contentType string) ([]byte, error) {
+	defer c.Timings.Track("request "+endpoint, time.Now())
+
 	url := fmt.Sprintf("%s/%s", c.BaseURL, endpoint)
 	req, err := http.NewRequest("POST", url, body)
 	if err != nil {
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/anaskhan96/soup"
+)
+
+// GetConfiguration reads the properties listed on HAC's Platform >
+// Configuration page.
+func (c *HACClient) GetConfiguration() (map[string]string, error) {
+	logger.Info("Reading configuration")
+
+	body, err := c.Get("platform/config")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	parseStart := time.Now()
+	defer c.Timings.Track("parse", parseStart)
+
+	doc := soup.HTMLParse(string(body))
+	table := doc.Find("table", "id", "props")
+	if table.Error != nil {
+		return nil, fmt.Errorf("configuration table not found in response")
+	}
+
+	properties := make(map[string]string)
+	for _, row := range table.FindAll("tr") {
+		cells := row.FindAll("td")
+		if len(cells) < 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(cells[0].FullText())
+		if key == "" {
+			continue
+		}
+
+		// The value is the content of an input so that it can be edited in place.
+		if input := cells[1].Find("input"); input.Error == nil {
+			properties[key] = input.Attrs()["value"]
+		} else {
+			properties[key] = strings.TrimSpace(cells[1].FullText())
+		}
+	}
+
+	logger.Debug("Parsed %d properties", len(properties))
+	return properties, nil
+}
+
+// SetConfiguration stores a property. Like the configuration page it only
+// changes the running configuration of the node that serves the request.
+func (c *HACClient) SetConfiguration(key, value string) error {
+	logger.Info("Setting configuration property %s", key)
+
+	formData := url.Values{}
+	formData.Set("key", key)
+	formData.Set("val", value)
+	formData.Set("_csrf", c.Csrf)
+
+	body, err := c.Post("platform/configstore", formData)
+	if err != nil {
+		return fmt.Errorf("failed to store property %s: %w", key, err)
+	}
+
+	logger.Debug("Response body: %s", string(body))
+	return nil
+}
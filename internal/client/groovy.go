@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/models"
@@ -25,10 +26,12 @@ func (c *HACClient) ExecuteGroovy(data map[string]any) (*models.GroovyResponse,
 
 	logger.Debug("Response body: %s", string(body))
 
+	parseStart := time.Now()
 	var result models.GroovyResponse
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w, body: %s", err, string(body))
 	}
+	c.Timings.Track("parse", parseStart)
 
 	return &result, nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"mime/multipart"
 	"net/url"
+	"time"
 
 	"github.com/anaskhan96/soup"
 	"github.com/Salvadego/HacTools/internal/logger"
@@ -26,8 +27,10 @@ func (c *HACClient) ImportScriptImpex(data map[string]any) (string, error) {
 
 	logger.Debug("Response body: %s", string(body))
 
+	parseStart := time.Now()
 	doc := soup.HTMLParse(string(body))
 	resultTag := doc.Find("div", "class", "impexResult")
+	c.Timings.Track("parse", parseStart)
 	var result string
 	if resultTag.Error != nil {
 		result = ""
@@ -47,8 +50,10 @@ func (c *HACClient) ImportFileImpex(body *bytes.Buffer, writer *multipart.Writer
 
 	logger.Debug("Upload response body: %s", string(resp))
 
+	parseStart := time.Now()
 	doc := soup.HTMLParse(string(resp))
 	resultTag := doc.Find("div", "class", "impexResult")
+	c.Timings.Track("parse", parseStart)
 
 	var result string
 	if resultTag.Error != nil {
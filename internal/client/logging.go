@@ -0,0 +1,110 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/anaskhan96/soup"
+)
+
+// GetLoggers reads the configured loggers and their levels from HAC's
+// Platform > Logging page.
+func (c *HACClient) GetLoggers() (map[string]string, error) {
+	logger.Info("Reading loggers")
+
+	body, err := c.Get("platform/log4j")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loggers: %w", err)
+	}
+
+	parseStart := time.Now()
+	defer c.Timings.Track("parse", parseStart)
+
+	doc := soup.HTMLParse(string(body))
+	loggers := make(map[string]string)
+	for _, row := range doc.FindAll("tr") {
+		cells := row.FindAll("td")
+		if len(cells) < 2 {
+			continue
+		}
+
+		name := strings.TrimSpace(cells[0].FullText())
+		if level := rowLevel(cells[1:]); name != "" && level != "" {
+			loggers[name] = level
+		}
+	}
+
+	if len(loggers) == 0 {
+		return nil, fmt.Errorf("no loggers found in response")
+	}
+
+	logger.Debug("Parsed %d loggers", len(loggers))
+	return loggers, nil
+}
+
+// rowLevel returns the level shown in a row of the logging page: the
+// selected option of the level dropdown, or the first cell naming a level.
+func rowLevel(cells []soup.Root) string {
+	for _, cell := range cells {
+		for _, option := range cell.FindAll("option") {
+			if _, selected := option.Attrs()["selected"]; selected {
+				return strings.ToUpper(strings.TrimSpace(option.FullText()))
+			}
+		}
+	}
+
+	for _, cell := range cells {
+		text := strings.ToUpper(strings.TrimSpace(cell.FullText()))
+		if isLevel(text) {
+			return text
+		}
+	}
+	return ""
+}
+
+func isLevel(level string) bool {
+	switch level {
+	case "ALL", "TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "OFF":
+		return true
+	}
+	return false
+}
+
+// SetLogLevel changes the level of a logger on the node that serves the
+// request.
+func (c *HACClient) SetLogLevel(name, level string) error {
+	logger.Info("Setting level of %s to %s", name, level)
+
+	formData := url.Values{}
+	formData.Set("loggerName", name)
+	formData.Set("levelName", level)
+	formData.Set("_csrf", c.Csrf)
+
+	body, err := c.Post("platform/log4j/changeLevel", formData)
+	if err != nil {
+		return fmt.Errorf("failed to change level of %s: %w", name, err)
+	}
+
+	logger.Debug("Response body: %s", string(body))
+
+	var result struct {
+		Loggers []struct {
+			Name           string `json:"name"`
+			EffectiveLevel string `json:"effectiveLevel"`
+		} `json:"loggers"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to decode response: %w, body: %s", err, string(body))
+	}
+
+	for _, l := range result.Loggers {
+		if l.Name == name && !strings.EqualFold(l.EffectiveLevel, level) {
+			return fmt.Errorf("level of %s is %s after changing it to %s", name, l.EffectiveLevel, level)
+		}
+	}
+	return nil
+}
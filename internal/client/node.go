@@ -0,0 +1,67 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+)
+
+// RouteCookie is the load balancer cookie that keeps a session on one node.
+const RouteCookie = "ROUTE"
+
+// PinNode sends all requests to one cluster node. node is either the HAC
+// URL of the node, or the value of its affinity cookie as value or
+// name=value (the name defaults to ROUTE). An empty node does nothing.
+func (c *HACClient) PinNode(node string) {
+	if node == "" {
+		return
+	}
+
+	if strings.HasPrefix(node, "http://") || strings.HasPrefix(node, "https://") {
+		if !strings.HasSuffix(node, "/") {
+			node += "/"
+		}
+		logger.Info("Pinning node %s", node)
+		c.BaseURL = node
+		return
+	}
+
+	name, value, ok := strings.Cut(node, "=")
+	if !ok {
+		name, value = RouteCookie, node
+	}
+	logger.Info("Pinning node with cookie %s=%s", name, value)
+	c.route = &http.Cookie{Name: name, Value: value, Path: "/"}
+	c.setRoute()
+}
+
+// Route returns the affinity cookie of the session as name=value, or an
+// empty string when the load balancer did not set one.
+func (c *HACClient) Route() string {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+
+	for _, cookie := range c.Client.Jar.Cookies(base) {
+		if (c.route != nil && cookie.Name == c.route.Name) || cookie.Name == RouteCookie {
+			return cookie.Name + "=" + cookie.Value
+		}
+	}
+	return ""
+}
+
+// setRoute puts the pinned affinity cookie into the cookie jar.
+func (c *HACClient) setRoute() {
+	if c.route == nil {
+		return
+	}
+
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return
+	}
+	c.Client.Jar.SetCookies(base, []*http.Cookie{c.route})
+}
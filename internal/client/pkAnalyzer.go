@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/models"
@@ -42,10 +43,12 @@ func (c *HACClient) AnalyzePK(pk string) (*models.PKAnalyzeResponse, error) {
 
 	logger.Debug("Response body from PK analyze: %s", string(body))
 
+	parseStart := time.Now()
 	var result models.PKAnalyzeResponse
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w, body: %s", err, string(body))
 	}
+	c.Timings.Track("parse", parseStart)
 
 	return &result, nil
 }
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+)
+
+var (
+	markupPattern = regexp.MustCompile(`<[^>]*>`)
+	// failurePattern marks the log lines of a streamed update that failed,
+	// since only JSON responses report the outcome.
+	failurePattern = regexp.MustCompile(`\b(ERROR|FAILED)\b`)
+	// loginPagePattern recognizes the login page HAC answers with when the
+	// session is not accepted.
+	loginPagePattern = regexp.MustCompile(`j_spring_security_check|name="j_username"`)
+)
+
+// InitUpdate updates or initializes the platform and copies the log the
+// server writes while it runs to w. The request blocks until the server is
+// done, which can take long.
+func (c *HACClient) InitUpdate(request models.InitUpdateRequest, w io.Writer) (*models.InitUpdateResult, error) {
+	defer c.Timings.Track("request platform/init/execute", time.Now())
+	logger.Info("Starting %s", strings.ToLower(request.InitMethod))
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	logger.Debug("Request body: %s", string(payload))
+
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+"platform/init/execute", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// The log page is written while the platform is updated, while the JSON
+	// response only arrives when it is done.
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, application/json;q=0.8")
+	req.Header.Set("X-CSRF-TOKEN", c.Csrf)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received HTTP %d error: %s", resp.StatusCode, string(body))
+	}
+
+	if !strings.HasSuffix(resp.Request.URL.Path, "platform/init/execute") {
+		return nil, fmt.Errorf("request was redirected to %s instead of running", resp.Request.URL)
+	}
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		var result models.InitUpdateResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		fmt.Fprintln(w, strings.TrimRight(result.Log, "\n"))
+		return &result, nil
+	}
+
+	// The log page is written while the platform is updated, so it is
+	// copied line by line as it arrives.
+	result := &models.InitUpdateResult{Success: true}
+	var log strings.Builder
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if loginPagePattern.MatchString(line) {
+			return nil, fmt.Errorf("received the login page instead of the log, the session was not accepted")
+		}
+		if text, ok := logLine(line); ok {
+			fmt.Fprintln(w, text)
+			log.WriteString(text + "\n")
+			if failurePattern.MatchString(text) {
+				result.Success = false
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log: %w", err)
+		}
+	}
+
+	if log.Len() == 0 {
+		return nil, fmt.Errorf("received no log, %s did not run", strings.ToLower(request.InitMethod))
+	}
+
+	result.Log = log.String()
+	return result, nil
+}
+
+// logLine returns the text of a line of the log page, or false for lines
+// that only hold markup.
+func logLine(line string) (string, bool) {
+	text := strings.TrimRight(html.UnescapeString(markupPattern.ReplaceAllString(line, "")), "\r\n")
+	if strings.TrimSpace(text) == "" {
+		return "", false
+	}
+	return text, true
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+)
+
+// GetCacheRegions reads the statistics of the region caches.
+func (c *HACClient) GetCacheRegions() ([]models.CacheRegion, error) {
+	logger.Info("Reading region caches")
+
+	formData := url.Values{}
+	formData.Set("_csrf", c.Csrf)
+
+	body, err := c.Post("monitoring/cache/regionCache", formData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read region caches: %w", err)
+	}
+
+	logger.Debug("Response body: %s", string(body))
+
+	parseStart := time.Now()
+	var regions []models.CacheRegion
+	if err := json.Unmarshal(body, &regions); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w, body: %s", err, string(body))
+	}
+	c.Timings.Track("parse", parseStart)
+
+	return regions, nil
+}
+
+// ClearCacheRegion clears a region cache on the node that serves the request.
+func (c *HACClient) ClearCacheRegion(name string) error {
+	logger.Info("Clearing region cache %s", name)
+
+	formData := url.Values{}
+	formData.Set("cacheName", name)
+	formData.Set("_csrf", c.Csrf)
+
+	body, err := c.Post("monitoring/cache/regionCache/clear", formData)
+	if err != nil {
+		return fmt.Errorf("failed to clear region cache %s: %w", name, err)
+	}
+
+	logger.Debug("Response body: %s", string(body))
+	return nil
+}
@@ -0,0 +1,286 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// staleAfter is the ping age after which a node is shown as stale.
+const staleAfter = time.Minute
+
+// nodesScript lists the nodes that pinged the cluster, including the node
+// running the script. Outside cluster mode only that node is listed.
+const nodesScript = `import de.hybris.platform.cluster.PingBroadcastHandler
+import de.hybris.platform.core.Registry
+import de.hybris.platform.util.Config
+import groovy.json.JsonOutput
+
+def value = { object, name -> object.hasProperty(name) ? object[name] : null }
+def current = Registry.clusterID
+def now = System.currentTimeMillis()
+
+def nodes = []
+if (Config.getBoolean('clustermode', false)) {
+    nodes = PingBroadcastHandler.instance.nodes.collect { node ->
+        def lastPing = value(node, 'lastPingTS')
+        [
+            id     : value(node, 'nodeID'),
+            host   : value(node, 'hostName'),
+            ip     : value(node, 'IP') ?: value(node, 'ip'),
+            pingAge: lastPing ? now - lastPing : null,
+        ]
+    }
+}
+
+if (!nodes.any { it.id == current }) {
+    def local = InetAddress.localHost
+    nodes << [id: current, host: local.hostName, ip: local.hostAddress, pingAge: 0]
+}
+nodes.each { it.current = it.id == current }
+
+return JsonOutput.toJson(nodes.sort { it.id })
+`
+
+// currentNodeScript describes the node running the script.
+const currentNodeScript = `import de.hybris.platform.core.Registry
+import groovy.json.JsonOutput
+
+def local = InetAddress.localHost
+return JsonOutput.toJson([id: Registry.clusterID, host: local.hostName, ip: local.hostAddress, pingAge: 0, current: true])
+`
+
+type ClusterManager struct {
+	Groovy *groovy.GroovyExecutor
+	// Output selects how DisplayNodes prints: text or json.
+	Output string
+}
+
+func NewClusterManager(client *client.HACClient) *ClusterManager {
+	return &ClusterManager{
+		Groovy: groovy.NewGroovyExecutor(client),
+	}
+}
+
+// Session is a logged-in client whose requests are served by Node.
+type Session struct {
+	Node   models.ClusterNode
+	Client *client.HACClient
+}
+
+// Nodes lists the cluster members. The node serving the request is marked
+// as current and carries the affinity cookie of the session.
+func (m *ClusterManager) Nodes() ([]models.ClusterNode, error) {
+	var nodes []models.ClusterNode
+	if err := m.run(nodesScript, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list cluster nodes: %w", err)
+	}
+
+	for i := range nodes {
+		if nodes[i].Current {
+			nodes[i].Route = m.Groovy.Client.Route()
+		}
+	}
+	return nodes, nil
+}
+
+// Current describes the node serving the requests of the session.
+func (m *ClusterManager) Current() (*models.ClusterNode, error) {
+	var node models.ClusterNode
+	if err := m.run(currentNodeScript, &node); err != nil {
+		return nil, fmt.Errorf("failed to identify node: %w", err)
+	}
+	node.Route = m.Groovy.Client.Route()
+	return &node, nil
+}
+
+// Sessions returns one session per cluster node, ordered by node id, and the
+// nodes no session could be opened on. login creates a logged-in client
+// pinned to the given node, or unpinned for an empty pin. With pins, each pin
+// is one node. Otherwise the nodes are discovered by logging in until the
+// load balancer assigned a session on every node, and each session keeps its
+// affinity cookie.
+func Sessions(login func(pin string) (*client.HACClient, error), pins []string) ([]Session, []models.ClusterNode, error) {
+	found := make(map[int]Session)
+	add := func(c *client.HACClient) (bool, error) {
+		node, err := NewClusterManager(c).Current()
+		if err != nil {
+			return false, err
+		}
+		if _, ok := found[node.ID]; ok {
+			return false, nil
+		}
+		c.PinNode(node.Route)
+		found[node.ID] = Session{Node: *node, Client: c}
+		return true, nil
+	}
+
+	if len(pins) > 0 {
+		for _, pin := range pins {
+			c, err := login(pin)
+			if err != nil {
+				return nil, nil, fmt.Errorf("node %s: %w", pin, err)
+			}
+			if added, err := add(c); err != nil {
+				return nil, nil, fmt.Errorf("node %s: %w", pin, err)
+			} else if !added {
+				return nil, nil, fmt.Errorf("node %s is served by the same cluster node as another pin", pin)
+			}
+		}
+		return sortSessions(found), nil, nil
+	}
+
+	first, err := login("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := NewClusterManager(first).Nodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := add(first); err != nil {
+		return nil, nil, err
+	}
+
+	if len(nodes) > 1 && first.Route() == "" {
+		logger.Error("The load balancer set no %s cookie, so requests may be served by any node; configure the nodes of the profile to pin them", client.RouteCookie)
+		return sortSessions(found), missingNodes(nodes, found), nil
+	}
+
+	attempts := max(5*len(nodes), 10)
+	for i := 0; i < attempts && len(found) < len(nodes); i++ {
+		c, err := login("")
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := add(c); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	missing := missingNodes(nodes, found)
+	for _, node := range missing {
+		logger.Error("Node %d (%s) was not reached after %d logins", node.ID, node.Host, attempts)
+	}
+	return sortSessions(found), missing, nil
+}
+
+// Execute runs a script through run on every session and returns one result
+// per node. A node that fails or has no session gets a failed result instead
+// of hiding the results of the others.
+func Execute(sessions []Session, missing []models.ClusterNode, run func(c *client.HACClient) (*models.GroovyResponse, error)) []*models.GroovyResponse {
+	results := make([]*models.GroovyResponse, 0, len(sessions)+len(missing))
+	for _, session := range sessions {
+		result, err := run(session.Client)
+		if err != nil {
+			result = &models.GroovyResponse{StacktraceText: fmt.Sprintf("failed to execute script: %v", err)}
+		}
+		result.Node = strconv.Itoa(session.Node.ID)
+		results = append(results, result)
+	}
+
+	for _, node := range missing {
+		results = append(results, &models.GroovyResponse{
+			StacktraceText: fmt.Sprintf("node %s was not reached, the script did not run there", node.Host),
+			Node:           strconv.Itoa(node.ID),
+		})
+	}
+	return results
+}
+
+func missingNodes(nodes []models.ClusterNode, found map[int]Session) []models.ClusterNode {
+	var missing []models.ClusterNode
+	for _, node := range nodes {
+		if _, ok := found[node.ID]; !ok {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
+
+func sortSessions(found map[int]Session) []Session {
+	sessions := make([]Session, 0, len(found))
+	for _, session := range found {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Node.ID < sessions[j].Node.ID })
+	return sessions
+}
+
+// Status describes the liveness of a node from its ping age.
+func Status(node models.ClusterNode) string {
+	switch {
+	case node.PingAge == nil:
+		return "unknown"
+	case time.Duration(*node.PingAge)*time.Millisecond < staleAfter:
+		return "alive"
+	default:
+		return "stale"
+	}
+}
+
+// run executes a curated script and decodes its JSON result into target.
+func (m *ClusterManager) run(script string, target any) error {
+	resp, err := m.Groovy.Execute(script, models.GroovyExecuteOptions{ScriptType: "groovy"})
+	if err != nil {
+		return err
+	}
+
+	if resp.StacktraceText != "" {
+		message, _, _ := strings.Cut(strings.TrimSpace(resp.StacktraceText), "\n")
+		return fmt.Errorf("script failed: %s", message)
+	}
+
+	if err := json.Unmarshal([]byte(resp.ExecutionResult), target); err != nil {
+		return fmt.Errorf("failed to decode result: %w, result: %s", err, resp.ExecutionResult)
+	}
+	return nil
+}
+
+func (m *ClusterManager) DisplayNodes(nodes []models.ClusterNode) error {
+	if m.Output == OutputJSON {
+		return output.JSON(nodes)
+	}
+
+	table := output.NewTable([]string{"ID", "Host", "IP", "Status", "Last ping", "Current", "Route"})
+
+	for _, node := range nodes {
+		lastPing := "-"
+		if node.PingAge != nil {
+			lastPing = (time.Duration(*node.PingAge) * time.Millisecond).Round(time.Second).String() + " ago"
+		}
+
+		current := ""
+		if node.Current {
+			current = "*"
+		}
+
+		table.Append([]string{
+			strconv.Itoa(node.ID),
+			node.Host,
+			node.IP,
+			Status(node),
+			lastPing,
+			current,
+			node.Route,
+		})
+	}
+	table.Render()
+	return nil
+}
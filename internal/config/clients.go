@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/options"
+)
+
+// ClientsDir is where haccli stores its client profiles.
+func ClientsDir() string {
+	dir, exists := os.LookupEnv("XDG_CONFIG_HOME")
+	if !exists || dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "haccli", "clients")
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "haccli", "clients")
+}
+
+// LoadClient reads the haccli profile name and returns base with the
+// address, credentials and environment of the profile and no pinned node.
+// The profile is a shell file of export lines, as written by haccli.
+func LoadClient(name string, base options.Config) (options.Config, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return base, fmt.Errorf("invalid profile name: %q", name)
+	}
+
+	file, err := os.Open(filepath.Join(ClientsDir(), name))
+	if err != nil {
+		return base, fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+	defer file.Close()
+
+	conf := base
+	conf.Profile = name
+	conf.Environment = ""
+	conf.Node = ""
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `'"`)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "HYBRIS_HAC_URL":
+			conf.Address = value
+		case "HYBRIS_USER":
+			conf.User = value
+		case "HYBRIS_PASSWORD":
+			conf.Password = value
+		case "HYBRIS_ENV":
+			conf.Environment = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return base, fmt.Errorf("failed to read profile %s: %w", name, err)
+	}
+
+	return conf, nil
+}
+
+// ProtectedTarget reports whether address belongs to a protected target: a
+// haccli profile on the same HAC server whose environment is protected, or
+// a host in protectedHosts. It returns a description of the match.
+func (c *Config) ProtectedTarget(address string) (string, bool) {
+	target := parseAddress(address)
+	if target == nil {
+		return "", false
+	}
+
+	for _, pattern := range c.ProtectedHosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), target.Hostname()); ok {
+			return "host " + pattern, true
+		}
+	}
+
+	entries, err := os.ReadDir(ClientsDir())
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		client, err := LoadClient(entry.Name(), options.Config{})
+		if err != nil {
+			continue
+		}
+		if server := parseAddress(client.Address); server == nil || server.Host != target.Host {
+			continue
+		}
+
+		environment := client.Environment
+		if environment == "" {
+			environment = c.EnvironmentFor(entry.Name())
+		}
+		if c.IsProtected(environment) {
+			return fmt.Sprintf("%s (profile %s)", environment, entry.Name()), true
+		}
+	}
+
+	return "", false
+}
+
+// parseAddress parses a HAC address into a lower case host with an explicit
+// port, so that addresses of the same server compare equal. An address
+// without a scheme is taken as https.
+func parseAddress(address string) *url.URL {
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if strings.EqualFold(u.Scheme, "http") {
+			port = "80"
+		}
+	}
+	u.Host = strings.ToLower(u.Hostname()) + ":" + port
+	return u
+}
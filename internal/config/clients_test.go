@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProtectedTarget(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	clients := filepath.Join(dir, "haccli", "clients")
+	if err := os.MkdirAll(clients, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	profiles := map[string]string{
+		"acme-prod":  "export HYBRIS_HAC_URL=\"https://hac.acme.com/hac\"\nexport HYBRIS_ENV=\"prod\"\n",
+		"acme-stage": "export HYBRIS_HAC_URL=\"https://stage.acme.com/hac\"\n",
+		"acme-dev":   "export HYBRIS_HAC_URL=\"https://dev.acme.com/hac\"\nexport HYBRIS_ENV=\"dev\"\n",
+		"local-prod": "export HYBRIS_HAC_URL=\"https://localhost:9102/hac\"\nexport HYBRIS_ENV=\"prod\"\n",
+	}
+	for name, content := range profiles {
+		if err := os.WriteFile(filepath.Join(clients, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := &Config{
+		ProtectedEnvironments: []string{"stage", "prod"},
+		ProtectedHosts:        []string{"*.prod.acme.com"},
+		Profiles:              map[string]Profile{"acme-stage": {Environment: "stage"}},
+	}
+
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"https://HAC.acme.com/hac", "prod (profile acme-prod)"},
+		{"hac.acme.com:443", "prod (profile acme-prod)"},
+		{"https://hac.acme.com:9002/hac", ""},
+		{"https://stage.acme.com/hac", "stage (profile acme-stage)"},
+		{"https://node1.prod.acme.com/hac", "host *.prod.acme.com"},
+		{"https://dev.acme.com/hac", ""},
+		{"https://localhost:9002/hac", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, ok := conf.ProtectedTarget(tt.address)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("ProtectedTarget() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/models"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultColumnBlacklist is used when the config file does not define a
+// global column blacklist.
+var DefaultColumnBlacklist = []string{
+	"hjmpTS",
+	"createdTS",
+	"modifiedTS",
+	"TypePkString",
+	"OwnerPkString",
+	"aCLTS",
+	"propTS",
+}
+
+// DefaultProtectedEnvironments is used when the config file does not list
+// the environments whose mutating operations need confirmation.
+var DefaultProtectedEnvironments = []string{"prod"}
+
+type Config struct {
+	Columns               models.ColumnConfig `yaml:"columns"`
+	ProtectedEnvironments []string            `yaml:"protectedEnvironments"`
+	// ProtectedHosts are host name patterns (path.Match syntax) that are
+	// protected whatever environment the target claims.
+	ProtectedHosts []string           `yaml:"protectedHosts"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+type Profile struct {
+	Columns     models.ColumnConfig `yaml:"columns"`
+	Environment string              `yaml:"environment"`
+	// Nodes pins each cluster node by HAC URL or affinity cookie, for
+	// operations on all nodes behind a load balancer without affinity.
+	Nodes []string `yaml:"nodes"`
+}
+
+func Path() string {
+	if path, exists := os.LookupEnv("HACTOOLS_CONFIG"); exists && path != "" {
+		return path
+	}
+	return filepath.Join(options.ConfigDir(), "config.yaml")
+}
+
+// Load reads the config file. A missing file yields the default configuration.
+func Load() (*Config, error) {
+	var conf Config
+
+	path := Path()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err == nil {
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if conf.Columns.Blacklist == nil {
+		conf.Columns.Blacklist = DefaultColumnBlacklist
+	}
+	if conf.ProtectedEnvironments == nil {
+		conf.ProtectedEnvironments = DefaultProtectedEnvironments
+	}
+	return &conf, nil
+}
+
+// ColumnsFor merges the global column rules with the rules of profile.
+func (c *Config) ColumnsFor(profile string) models.ColumnConfig {
+	columns := c.Columns
+	if p, ok := c.Profiles[profile]; ok {
+		columns = MergeColumns(columns, p.Columns)
+	}
+	return columns
+}
+
+// EnvironmentFor returns the environment class of profile (local, dev,
+// stage, prod, ...) or an empty string when it is not tagged.
+func (c *Config) EnvironmentFor(profile string) string {
+	return c.Profiles[profile].Environment
+}
+
+// NodesFor returns the configured node pins of profile.
+func (c *Config) NodesFor(profile string) []string {
+	return c.Profiles[profile].Nodes
+}
+
+// IsProtected reports whether mutating operations on environment need
+// confirmation.
+func (c *Config) IsProtected(environment string) bool {
+	return environment != "" && slices.ContainsFunc(c.ProtectedEnvironments, func(protected string) bool {
+		return strings.EqualFold(protected, environment)
+	})
+}
+
+// MergeColumns appends the patterns of override to base. Renames and type
+// rules of override take precedence.
+func MergeColumns(base, override models.ColumnConfig) models.ColumnConfig {
+	merged := models.ColumnConfig{
+		Blacklist: append(append([]string{}, base.Blacklist...), override.Blacklist...),
+		Whitelist: append(append([]string{}, base.Whitelist...), override.Whitelist...),
+		Rename:    make(map[string]string),
+		Types:     make(map[string]models.ColumnConfig),
+	}
+
+	for from, to := range base.Rename {
+		merged.Rename[from] = to
+	}
+	for from, to := range override.Rename {
+		merged.Rename[from] = to
+	}
+
+	for typeCode, rules := range base.Types {
+		merged.Types[typeCode] = rules
+	}
+	for typeCode, rules := range override.Types {
+		merged.Types[typeCode] = MergeColumns(merged.Types[typeCode], rules)
+	}
+
+	return merged
+}
@@ -0,0 +1,347 @@
+package cronjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Statuses are the values of the CronJobStatus enum.
+var Statuses = []string{"RUNNING", "RUNNINGRESTART", "FINISHED", "ABORTED", "PAUSED", "UNKNOWN"}
+
+// scriptPreamble describes a CronJob as a map. The scripts below get their
+// arguments bound as variables by the executor.
+const scriptPreamble = `import de.hybris.platform.cronjob.enums.CronJobStatus
+import groovy.json.JsonOutput
+
+def describe = { job ->
+    [
+        code     : job.code,
+        type     : job.itemtype,
+        job      : job.job?.code,
+        status   : job.status?.code,
+        result   : job.result?.code,
+        active   : job.active,
+        startTime: job.startTime?.time ?: 0,
+        endTime  : job.endTime?.time ?: 0,
+        node     : job.nodeID,
+    ]
+}
+`
+
+const listScript = scriptPreamble + `
+def query = 'SELECT {pk} FROM {CronJob}'
+def params = [:]
+if (status) {
+    query += ' WHERE {status} = ?status'
+    params.status = CronJobStatus.valueOf(status)
+}
+query += ' ORDER BY {code}'
+
+return JsonOutput.toJson(flexibleSearchService.search(query, params).result.collect(describe))
+`
+
+const getScript = scriptPreamble + `
+return JsonOutput.toJson(describe(cronJobService.getCronJob(code)))
+`
+
+// runScript returns the CronJob as it was before it was triggered so that
+// the caller can tell the new run from the previous one.
+const runScript = scriptPreamble + `
+def job = cronJobService.getCronJob(code)
+if (cronJobService.isRunning(job)) {
+    throw new IllegalStateException("CronJob ${code} is already running")
+}
+
+def before = describe(job)
+cronJobService.performCronJob(job, false)
+return JsonOutput.toJson(before)
+`
+
+const abortScript = scriptPreamble + `
+def job = cronJobService.getCronJob(code)
+if (!cronJobService.isRunning(job)) {
+    throw new IllegalStateException("CronJob ${code} is not running")
+}
+
+cronJobService.requestAbortCronJob(job)
+return JsonOutput.toJson(describe(job))
+`
+
+// logsScript returns the newest log file of the CronJob, or its job log
+// entries when it has no log files.
+const logsScript = `import groovy.json.JsonOutput
+import java.text.SimpleDateFormat
+
+def job = cronJobService.getCronJob(code)
+def files = (job.logFiles ?: []).sort { it.creationtime }
+if (files) {
+    def media = files.last()
+    def text = mediaService.getStreamFromMedia(media).getText('UTF-8')
+    return JsonOutput.toJson([code: code, source: media.code, text: text])
+}
+
+def format = new SimpleDateFormat('yyyy-MM-dd HH:mm:ss')
+def lines = (job.logs ?: []).sort { it.creationtime }.collect {
+    "${format.format(it.creationtime)} ${it.level?.code} ${it.message}"
+}
+return JsonOutput.toJson([code: code, source: '', text: lines.join('\n')])
+`
+
+const historyScript = `import groovy.json.JsonOutput
+
+def job = cronJobService.getCronJob(code)
+def entries = (job.cronJobHistoryEntries ?: []).sort { it.startTime }.reverse()
+return JsonOutput.toJson(entries.collect {
+    [
+        startTime: it.startTime?.time ?: 0,
+        endTime  : it.endTime?.time ?: 0,
+        status   : it.status?.code,
+        result   : it.result?.code,
+        user     : it.userUID,
+        node     : it.nodeID,
+    ]
+})
+`
+
+type CronJobManager struct {
+	Groovy *groovy.GroovyExecutor
+	// Output selects how the Display functions print: text or json.
+	Output string
+}
+
+func NewCronJobManager(client *client.HACClient) *CronJobManager {
+	return &CronJobManager{
+		Groovy: groovy.NewGroovyExecutor(client),
+	}
+}
+
+// List returns the CronJobs ordered by code, only those with the given
+// status when status is set.
+func (m *CronJobManager) List(status string) ([]models.CronJob, error) {
+	status = strings.ToUpper(status)
+	if status != "" && !slices.Contains(Statuses, status) {
+		return nil, fmt.Errorf("invalid status: %s (must be one of %s)", status, strings.Join(Statuses, ", "))
+	}
+
+	var jobs []models.CronJob
+	if err := m.run(listScript, map[string]any{"status": status}, false, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Get returns the current state of a CronJob.
+func (m *CronJobManager) Get(code string) (*models.CronJob, error) {
+	var job models.CronJob
+	if err := m.run(getScript, map[string]any{"code": code}, false, &job); err != nil {
+		return nil, fmt.Errorf("failed to read cronjob %s: %w", code, err)
+	}
+	return &job, nil
+}
+
+// Run triggers a CronJob asynchronously and returns its state before the run.
+func (m *CronJobManager) Run(code string) (*models.CronJob, error) {
+	var job models.CronJob
+	if err := m.run(runScript, map[string]any{"code": code}, true, &job); err != nil {
+		return nil, fmt.Errorf("failed to run cronjob %s: %w", code, err)
+	}
+	return &job, nil
+}
+
+// Abort requests a running CronJob to abort. Only jobs whose performable
+// supports aborting stop.
+func (m *CronJobManager) Abort(code string) (*models.CronJob, error) {
+	var job models.CronJob
+	if err := m.run(abortScript, map[string]any{"code": code}, true, &job); err != nil {
+		return nil, fmt.Errorf("failed to abort cronjob %s: %w", code, err)
+	}
+	return &job, nil
+}
+
+// Logs returns the most recent log of a CronJob.
+func (m *CronJobManager) Logs(code string) (*models.CronJobLogs, error) {
+	var logs models.CronJobLogs
+	if err := m.run(logsScript, map[string]any{"code": code}, false, &logs); err != nil {
+		return nil, fmt.Errorf("failed to read logs of cronjob %s: %w", code, err)
+	}
+	return &logs, nil
+}
+
+// History returns the past runs of a CronJob, newest first.
+func (m *CronJobManager) History(code string) ([]models.CronJobRun, error) {
+	var runs []models.CronJobRun
+	if err := m.run(historyScript, map[string]any{"code": code}, false, &runs); err != nil {
+		return nil, fmt.Errorf("failed to read history of cronjob %s: %w", code, err)
+	}
+	return runs, nil
+}
+
+// Wait polls a CronJob every interval until the run that started after
+// previous finishes, and returns its final state. A zero timeout waits
+// forever.
+func (m *CronJobManager) Wait(previous *models.CronJob, interval, timeout time.Duration) (*models.CronJob, error) {
+	start := time.Now()
+	for {
+		job, err := m.Get(previous.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		started := job.StartTime != previous.StartTime
+		if started && (job.Status == "FINISHED" || job.Status == "ABORTED") {
+			return job, nil
+		}
+
+		if timeout > 0 && time.Since(start) > timeout {
+			return nil, fmt.Errorf("timed out after %s waiting for cronjob %s (status %s)", timeout, job.Code, job.Status)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Failed reports whether a finished run of job did not succeed.
+func Failed(job *models.CronJob) bool {
+	return job.Status == "ABORTED" || job.Result == "ERROR" || job.Result == "FAILURE"
+}
+
+// run executes a curated script with vars bound and decodes its JSON result
+// into target.
+func (m *CronJobManager) run(script string, vars map[string]any, commit bool, target any) error {
+	resp, err := m.Groovy.Execute(script, models.GroovyExecuteOptions{
+		ScriptType: "groovy",
+		Commit:     commit,
+		Vars:       vars,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.StacktraceText != "" {
+		message, _, _ := strings.Cut(strings.TrimSpace(resp.StacktraceText), "\n")
+		return fmt.Errorf("script failed: %s", message)
+	}
+
+	if err := json.Unmarshal([]byte(resp.ExecutionResult), target); err != nil {
+		return fmt.Errorf("failed to decode result: %w, result: %s", err, resp.ExecutionResult)
+	}
+	return nil
+}
+
+func (m *CronJobManager) DisplayJobs(jobs []models.CronJob) error {
+	if m.Output == OutputJSON {
+		return output.JSON(jobs)
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("No cronjobs found")
+		return nil
+	}
+
+	table := output.NewTable([]string{"Code", "Job", "Status", "Result", "Active", "Start", "End", "Node"})
+	for _, job := range jobs {
+		table.Append([]string{
+			job.Code,
+			job.Job,
+			job.Status,
+			job.Result,
+			strconv.FormatBool(job.Active),
+			formatMillis(job.StartTime),
+			formatMillis(job.EndTime),
+			formatNode(job.Node),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func (m *CronJobManager) DisplayJob(job *models.CronJob) error {
+	if m.Output == OutputJSON {
+		return output.JSON(job)
+	}
+
+	fmt.Printf("Code:     %s\n", job.Code)
+	fmt.Printf("Type:     %s\n", job.Type)
+	fmt.Printf("Job:      %s\n", job.Job)
+	fmt.Printf("Status:   %s\n", job.Status)
+	fmt.Printf("Result:   %s\n", job.Result)
+	fmt.Printf("Started:  %s\n", formatMillis(job.StartTime))
+	fmt.Printf("Finished: %s\n", formatMillis(job.EndTime))
+	fmt.Printf("Duration: %s\n", duration(job.StartTime, job.EndTime))
+	fmt.Printf("Node:     %s\n", formatNode(job.Node))
+	return nil
+}
+
+func (m *CronJobManager) DisplayLogs(logs *models.CronJobLogs) error {
+	if m.Output == OutputJSON {
+		return output.JSON(logs)
+	}
+
+	if logs.Text == "" {
+		fmt.Printf("No logs found for cronjob %s\n", logs.Code)
+		return nil
+	}
+
+	fmt.Println(strings.TrimRight(logs.Text, "\n"))
+	return nil
+}
+
+func (m *CronJobManager) DisplayHistory(runs []models.CronJobRun) error {
+	if m.Output == OutputJSON {
+		return output.JSON(runs)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No history entries found")
+		return nil
+	}
+
+	table := output.NewTable([]string{"Start", "End", "Duration", "Status", "Result", "User", "Node"})
+	for _, run := range runs {
+		table.Append([]string{
+			formatMillis(run.StartTime),
+			formatMillis(run.EndTime),
+			duration(run.StartTime, run.EndTime),
+			run.Status,
+			run.Result,
+			run.User,
+			formatNode(run.Node),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func formatMillis(millis int64) string {
+	if millis == 0 {
+		return "-"
+	}
+	return time.UnixMilli(millis).Format("2006-01-02 15:04:05")
+}
+
+func formatNode(node *int) string {
+	if node == nil {
+		return "-"
+	}
+	return strconv.Itoa(*node)
+}
+
+func duration(start, end int64) string {
+	if start == 0 || end < start {
+		return "-"
+	}
+	return (time.Duration(end-start) * time.Millisecond).Round(time.Second).String()
+}
@@ -0,0 +1,505 @@
+package diagnostics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/groovy"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// States are the thread states in the order they are displayed.
+var States = []string{"RUNNABLE", "BLOCKED", "WAITING", "TIMED_WAITING", "NEW", "TERMINATED"}
+
+// threadsScript dumps every thread of the node with the JVM's thread bean,
+// including the monitors and synchronizers the threads wait for.
+const threadsScript = `import java.lang.management.ManagementFactory
+import groovy.json.JsonOutput
+
+def bean = ManagementFactory.threadMXBean
+def infos = bean.dumpAllThreads(bean.objectMonitorUsageSupported, bean.synchronizerUsageSupported)
+def threads = infos.findAll { it != null }.collect { info ->
+    [
+        id       : info.threadId,
+        name     : info.threadName,
+        state    : info.threadState.name(),
+        daemon   : info.daemon,
+        lockName : info.lockName,
+        lockOwner: info.lockOwnerName,
+        stack    : info.stackTrace.collect { it.toString() },
+    ]
+}
+
+return JsonOutput.toJson([host: InetAddress.localHost.hostName, time: System.currentTimeMillis(), threads: threads])
+`
+
+const memoryScript = `import java.lang.management.ManagementFactory
+import groovy.json.JsonOutput
+
+def usage = { u -> u ? [init: u.init, used: u.used, committed: u.committed, max: u.max] : null }
+def memory = ManagementFactory.memoryMXBean
+
+return JsonOutput.toJson([
+    host             : InetAddress.localHost.hostName,
+    time             : System.currentTimeMillis(),
+    uptime           : ManagementFactory.runtimeMXBean.uptime,
+    heap             : usage(memory.heapMemoryUsage),
+    nonHeap          : usage(memory.nonHeapMemoryUsage),
+    pools            : ManagementFactory.memoryPoolMXBeans.collect { [name: it.name, type: it.type.name(), usage: usage(it.usage)] },
+    garbageCollectors: ManagementFactory.garbageCollectorMXBeans.collect { [name: it.name, count: it.collectionCount, time: it.collectionTime] },
+])
+`
+
+// poolSuffix is the number that tells the threads of a pool apart.
+var poolSuffix = regexp.MustCompile(`[\s\-_#.]*\d+$`)
+
+// threadHeader is the first line of a thread in a dump file, as written by
+// WriteThreadDump and jstack: "name" #id daemon ...
+var threadHeader = regexp.MustCompile(`^"(.*)"(?:\s+#(\d+))?(?:\s+\[\d+\])?(\s+daemon)?`)
+
+type DiagnosticsManager struct {
+	Groovy *groovy.GroovyExecutor
+	// Output selects how the Display functions print: text or json.
+	Output string
+}
+
+func NewDiagnosticsManager(client *client.HACClient) *DiagnosticsManager {
+	return &DiagnosticsManager{
+		Groovy: groovy.NewGroovyExecutor(client),
+	}
+}
+
+// Threads dumps the threads of the node serving the session.
+func (m *DiagnosticsManager) Threads() (*models.ThreadDump, error) {
+	var dump models.ThreadDump
+	if err := m.run(threadsScript, &dump); err != nil {
+		return nil, fmt.Errorf("failed to dump threads: %w", err)
+	}
+	return &dump, nil
+}
+
+// Memory reads the heap, non-heap and garbage collection figures of the node
+// serving the session.
+func (m *DiagnosticsManager) Memory() (*models.MemoryInfo, error) {
+	var memory models.MemoryInfo
+	if err := m.run(memoryScript, &memory); err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+	return &memory, nil
+}
+
+// Capture takes count thread dumps, interval apart, and writes each one to
+// a timestamped file in dir. written is called with the path of each file.
+func (m *DiagnosticsManager) Capture(count int, interval time.Duration, dir string, written func(path string, dump *models.ThreadDump)) ([]models.ThreadDump, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid count: %d (must be at least 1)", count)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	dumps := make([]models.ThreadDump, 0, count)
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		dump, err := m.Threads()
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, DumpFileName(dump))
+		if err := WriteThreadDumpFile(path, dump); err != nil {
+			return nil, err
+		}
+		if written != nil {
+			written(path, dump)
+		}
+		dumps = append(dumps, *dump)
+	}
+	return dumps, nil
+}
+
+// DumpFileName names the file of a dump after its node and time.
+func DumpFileName(dump *models.ThreadDump) string {
+	host := dump.Host
+	if host == "" {
+		host = "node"
+	}
+	host = strings.NewReplacer("/", "_", `\`, "_", " ", "_").Replace(host)
+	return fmt.Sprintf("threads-%s-%s.txt", host, time.UnixMilli(dump.Time).Format("20060102-150405.000"))
+}
+
+func WriteThreadDumpFile(path string, dump *models.ThreadDump) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	WriteThreadDump(writer, dump)
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteThreadDump writes dump in the text format of jstack, so that the
+// files can be read by thread dump analyzers and by ReadThreadDump.
+func WriteThreadDump(w io.Writer, dump *models.ThreadDump) {
+	fmt.Fprintf(w, "%s\nFull thread dump of %s\n\n", time.UnixMilli(dump.Time).Format("2006-01-02 15:04:05"), dump.Host)
+
+	for _, thread := range dump.Threads {
+		daemon := ""
+		if thread.Daemon {
+			daemon = " daemon"
+		}
+		fmt.Fprintf(w, "%q #%d%s\n", thread.Name, thread.ID, daemon)
+		fmt.Fprintf(w, "   java.lang.Thread.State: %s\n", thread.State)
+
+		for i, frame := range thread.Stack {
+			fmt.Fprintf(w, "\tat %s\n", frame)
+			if i == 0 && thread.LockName != "" {
+				owner := ""
+				if thread.LockOwner != "" {
+					owner = fmt.Sprintf(" owned by %q", thread.LockOwner)
+				}
+				fmt.Fprintf(w, "\t- waiting on <%s>%s\n", thread.LockName, owner)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// ReadThreadDump reads a dump written by WriteThreadDump or jstack. Lock
+// details are not read back, and the host and time default to the name and
+// modification time of the file.
+func ReadThreadDump(path string) (*models.ThreadDump, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	dump := &models.ThreadDump{Host: filepath.Base(path)}
+	if info, err := file.Stat(); err == nil {
+		dump.Time = info.ModTime().UnixMilli()
+	}
+
+	// current is the index of the thread being read, -1 before the first.
+	current := -1
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, `"`):
+			match := threadHeader.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			thread := models.ThreadInfo{Name: match[1], Daemon: match[3] != ""}
+			if name, err := strconv.Unquote(`"` + match[1] + `"`); err == nil {
+				thread.Name = name
+			}
+			thread.ID, _ = strconv.ParseInt(match[2], 10, 64)
+			dump.Threads = append(dump.Threads, thread)
+			current = len(dump.Threads) - 1
+		case current < 0 && strings.HasPrefix(trimmed, "Full thread dump of "):
+			dump.Host = strings.TrimPrefix(trimmed, "Full thread dump of ")
+		case current < 0:
+			if at, err := time.ParseInLocation("2006-01-02 15:04:05", trimmed, time.Local); err == nil {
+				dump.Time = at.UnixMilli()
+			}
+		case strings.HasPrefix(trimmed, "java.lang.Thread.State:"):
+			state := strings.TrimSpace(strings.TrimPrefix(trimmed, "java.lang.Thread.State:"))
+			dump.Threads[current].State, _, _ = strings.Cut(state, " ")
+		case strings.HasPrefix(trimmed, "at "):
+			dump.Threads[current].Stack = append(dump.Threads[current].Stack, strings.TrimPrefix(trimmed, "at "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if len(dump.Threads) == 0 {
+		return nil, fmt.Errorf("no threads found in %s", path)
+	}
+	return dump, nil
+}
+
+// Summarize groups the threads of the last dump by pool and by state and
+// their top depth frames. With several dumps of the same node, the threads
+// that kept the same state and stack in all of them are counted as
+// unchanged, which points at stuck threads.
+func Summarize(dumps []models.ThreadDump, depth int) models.ThreadSummary {
+	last := dumps[len(dumps)-1]
+	summary := models.ThreadSummary{
+		Host:    last.Host,
+		Time:    last.Time,
+		Threads: len(last.Threads),
+		States:  make(map[string]int),
+	}
+
+	pools := make(map[string]*models.ThreadPool)
+	stacks := make(map[string]*models.ThreadStack)
+	var order []string
+	for _, thread := range last.Threads {
+		summary.States[thread.State]++
+
+		name := poolSuffix.ReplaceAllString(thread.Name, "")
+		if name == "" {
+			name = thread.Name
+		}
+		pool, ok := pools[name]
+		if !ok {
+			pool = &models.ThreadPool{Name: name, States: make(map[string]int)}
+			pools[name] = pool
+		}
+		pool.Threads++
+		pool.States[thread.State]++
+
+		frames := thread.Stack
+		if depth > 0 && len(frames) > depth {
+			frames = frames[:depth]
+		}
+		key := thread.State + "\n" + strings.Join(frames, "\n")
+		stack, ok := stacks[key]
+		if !ok {
+			stack = &models.ThreadStack{State: thread.State, Frames: frames}
+			stacks[key] = stack
+			order = append(order, key)
+		}
+		stack.Threads = append(stack.Threads, thread.Name)
+		if len(dumps) > 1 && unchanged(dumps, thread) {
+			stack.Unchanged++
+		}
+	}
+
+	for _, pool := range pools {
+		summary.Pools = append(summary.Pools, *pool)
+	}
+	sort.Slice(summary.Pools, func(i, j int) bool {
+		a, b := summary.Pools[i], summary.Pools[j]
+		if a.States["BLOCKED"] != b.States["BLOCKED"] {
+			return a.States["BLOCKED"] > b.States["BLOCKED"]
+		}
+		if a.Threads != b.Threads {
+			return a.Threads > b.Threads
+		}
+		return a.Name < b.Name
+	})
+
+	for _, key := range order {
+		summary.Stacks = append(summary.Stacks, *stacks[key])
+	}
+	sort.SliceStable(summary.Stacks, func(i, j int) bool {
+		return len(summary.Stacks[i].Threads) > len(summary.Stacks[j].Threads)
+	})
+	return summary
+}
+
+// unchanged reports whether thread has the same state and stack in every
+// dump. Threads are matched by id and name, as ids are reused.
+func unchanged(dumps []models.ThreadDump, thread models.ThreadInfo) bool {
+	for _, dump := range dumps {
+		found := false
+		for _, other := range dump.Threads {
+			if other.ID != thread.ID || other.Name != thread.Name {
+				continue
+			}
+			if other.State != thread.State || strings.Join(other.Stack, "\n") != strings.Join(thread.Stack, "\n") {
+				return false
+			}
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// run executes a curated script and decodes its JSON result into target.
+func (m *DiagnosticsManager) run(script string, target any) error {
+	resp, err := m.Groovy.Execute(script, models.GroovyExecuteOptions{ScriptType: "groovy"})
+	if err != nil {
+		return err
+	}
+
+	if resp.StacktraceText != "" {
+		message, _, _ := strings.Cut(strings.TrimSpace(resp.StacktraceText), "\n")
+		return fmt.Errorf("script failed: %s", message)
+	}
+
+	if err := json.Unmarshal([]byte(resp.ExecutionResult), target); err != nil {
+		return fmt.Errorf("failed to decode result: %w, result: %s", err, resp.ExecutionResult)
+	}
+	return nil
+}
+
+// DisplaySummary prints the state counts, the top pools and the top stacks
+// of a summary. dumps is the number of dumps it was made from.
+func (m *DiagnosticsManager) DisplaySummary(summary models.ThreadSummary, dumps, top int) error {
+	if m.Output == OutputJSON {
+		return output.JSON(summary)
+	}
+
+	fmt.Printf("%d threads on %s at %s\n", summary.Threads, summary.Host, time.UnixMilli(summary.Time).Format("2006-01-02 15:04:05"))
+	var states []string
+	for _, state := range States {
+		if count := summary.States[state]; count > 0 {
+			states = append(states, fmt.Sprintf("%s %d", state, count))
+		}
+	}
+	fmt.Println(strings.Join(states, ", "))
+	fmt.Println()
+
+	table := output.NewTable([]string{"Pool", "Threads", "Runnable", "Blocked", "Waiting", "Timed waiting"})
+	for i, pool := range summary.Pools {
+		if top > 0 && i == top {
+			break
+		}
+		table.Append([]string{
+			pool.Name,
+			strconv.Itoa(pool.Threads),
+			strconv.Itoa(pool.States["RUNNABLE"]),
+			strconv.Itoa(pool.States["BLOCKED"]),
+			strconv.Itoa(pool.States["WAITING"]),
+			strconv.Itoa(pool.States["TIMED_WAITING"]),
+		})
+	}
+	table.Render()
+
+	for i, stack := range summary.Stacks {
+		if top > 0 && i == top {
+			break
+		}
+
+		fmt.Println()
+		unchanged := ""
+		if dumps > 1 && stack.Unchanged > 0 {
+			unchanged = fmt.Sprintf(", %d unchanged in %d dumps", stack.Unchanged, dumps)
+		}
+		fmt.Printf("%d %s %s%s\n", len(stack.Threads), plural(len(stack.Threads), "thread"), stack.State, unchanged)
+		fmt.Printf("  %s\n", threadNames(stack.Threads, 5))
+		for _, frame := range stack.Frames {
+			fmt.Printf("    at %s\n", frame)
+		}
+	}
+	return nil
+}
+
+func (m *DiagnosticsManager) DisplayMemory(memory *models.MemoryInfo) error {
+	if m.Output == OutputJSON {
+		return output.JSON(memory)
+	}
+
+	uptime := (time.Duration(memory.Uptime) * time.Millisecond).Round(time.Minute)
+	fmt.Printf("Memory of %s at %s, up %s\n\n", memory.Host, time.UnixMilli(memory.Time).Format("2006-01-02 15:04:05"), uptime)
+
+	table := output.NewTable([]string{"Area", "Type", "Used", "Committed", "Max", "Usage"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	appendUsage := func(name, kind string, usage models.MemoryUsage) {
+		table.Append([]string{
+			name,
+			kind,
+			formatBytes(usage.Used),
+			formatBytes(usage.Committed),
+			formatBytes(usage.Max),
+			percent(usage.Used, usage.Max),
+		})
+	}
+
+	appendUsage("Heap", "", memory.Heap)
+	appendUsage("Non-heap", "", memory.NonHeap)
+	for _, pool := range memory.Pools {
+		appendUsage(pool.Name, pool.Type, pool.Usage)
+	}
+	table.Render()
+
+	if len(memory.GarbageCollectors) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	gc := output.NewTable([]string{"Collector", "Collections", "Time", "Average"})
+	gc.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for _, collector := range memory.GarbageCollectors {
+		average := "-"
+		if collector.Count > 0 {
+			average = (time.Duration(collector.Time/collector.Count) * time.Millisecond).String()
+		}
+		gc.Append([]string{
+			collector.Name,
+			strconv.FormatInt(collector.Count, 10),
+			(time.Duration(collector.Time) * time.Millisecond).String(),
+			average,
+		})
+	}
+	gc.Render()
+	return nil
+}
+
+// threadNames lists up to limit names and how many were left out.
+func threadNames(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
+
+func plural(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+func formatBytes(bytes int64) string {
+	if bytes < 0 {
+		return "-"
+	}
+
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func percent(part, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+}
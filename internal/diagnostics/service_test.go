@@ -0,0 +1,140 @@
+package diagnostics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestThreadDumpRoundTrip(t *testing.T) {
+	at := time.Date(2024, 3, 1, 10, 15, 30, 0, time.Local)
+	dump := &models.ThreadDump{
+		Host: "app-1",
+		Time: at.UnixMilli(),
+		Threads: []models.ThreadInfo{
+			{ID: 7, Name: `hybrisHTTP"3"`, State: "BLOCKED", Daemon: true, LockName: "java.lang.Object@1a", LockOwner: "hybrisHTTP1",
+				Stack: []string{"de.hybris.Cache.get(Cache.java:10)", "de.hybris.Service.run(Service.java:20)"}},
+			{ID: 8, Name: "main", State: "RUNNABLE", Stack: []string{"java.lang.Thread.run(Thread.java:833)"}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "dump.txt")
+	if err := WriteThreadDumpFile(path, dump); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadThreadDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lock details are written for reading only and are not parsed back.
+	want := *dump
+	want.Threads = []models.ThreadInfo{dump.Threads[0], dump.Threads[1]}
+	want.Threads[0].LockName, want.Threads[0].LockOwner = "", ""
+	if !reflect.DeepEqual(got, &want) {
+		t.Errorf("ReadThreadDump() = %+v, want %+v", got, &want)
+	}
+}
+
+func TestReadJstackDump(t *testing.T) {
+	const jstack = `2024-03-01 10:15:30
+Full thread dump OpenJDK 64-Bit Server VM (17.0.9+9 mixed mode):
+
+"main" #1 prio=5 os_prio=0 tid=0x00007f nid=0x1 waiting on condition  [0x00007f]
+   java.lang.Thread.State: TIMED_WAITING (sleeping)
+	at java.lang.Thread.sleep(java.base@17.0.9/Native Method)
+	at de.hybris.Main.run(Main.java:42)
+
+"Reference Handler" #2 daemon prio=10 os_prio=0 tid=0x00007f nid=0x2 runnable  [0x00007f]
+   java.lang.Thread.State: RUNNABLE
+	at java.lang.ref.Reference.waitForReferencePendingList(java.base@17.0.9/Native Method)
+	- locked <0x000000070> (a java.lang.Object)
+
+"VM Thread" os_prio=0 tid=0x00007f nid=0x3 runnable
+
+JNI global refs: 15, weak refs: 0
+`
+	path := filepath.Join(t.TempDir(), "node1.txt")
+	if err := os.WriteFile(path, []byte(jstack), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dump, err := ReadThreadDump(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dump.Host != "node1.txt" {
+		t.Errorf("Host = %q, want the file name", dump.Host)
+	}
+	if want := time.Date(2024, 3, 1, 10, 15, 30, 0, time.Local).UnixMilli(); dump.Time != want {
+		t.Errorf("Time = %d, want %d", dump.Time, want)
+	}
+
+	want := []models.ThreadInfo{
+		{ID: 1, Name: "main", State: "TIMED_WAITING", Stack: []string{"java.lang.Thread.sleep(java.base@17.0.9/Native Method)", "de.hybris.Main.run(Main.java:42)"}},
+		{ID: 2, Name: "Reference Handler", State: "RUNNABLE", Daemon: true, Stack: []string{"java.lang.ref.Reference.waitForReferencePendingList(java.base@17.0.9/Native Method)"}},
+		{Name: "VM Thread"},
+	}
+	if !reflect.DeepEqual(dump.Threads, want) {
+		t.Errorf("Threads = %+v, want %+v", dump.Threads, want)
+	}
+
+	if err := os.WriteFile(path, []byte("nothing here\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadThreadDump(path); err == nil || !strings.Contains(err.Error(), "no threads found") {
+		t.Errorf("ReadThreadDump() error = %v, want no threads found", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	stuck := []string{"de.hybris.Cache.get(Cache.java:10)", "de.hybris.Service.run(Service.java:20)", "java.lang.Thread.run(Thread.java:833)"}
+	moving := []string{"de.hybris.Importer.next(Importer.java:5)"}
+
+	first := models.ThreadDump{Threads: []models.ThreadInfo{
+		{ID: 1, Name: "hybrisHTTP1", State: "BLOCKED", Stack: stuck},
+		{ID: 2, Name: "hybrisHTTP2", State: "BLOCKED", Stack: stuck},
+		{ID: 3, Name: "ImpExWorker-7", State: "RUNNABLE", Stack: []string{"de.hybris.Importer.read(Importer.java:1)"}},
+	}}
+	last := models.ThreadDump{Host: "app-0", Time: 1000, Threads: []models.ThreadInfo{
+		{ID: 1, Name: "hybrisHTTP1", State: "BLOCKED", Stack: stuck},
+		{ID: 2, Name: "hybrisHTTP2", State: "BLOCKED", Stack: stuck},
+		{ID: 3, Name: "ImpExWorker-7", State: "RUNNABLE", Stack: moving},
+		{ID: 4, Name: "main", State: "RUNNABLE", Stack: moving},
+	}}
+
+	summary := Summarize([]models.ThreadDump{first, last}, 2)
+	if summary.Host != "app-0" || summary.Threads != 4 {
+		t.Errorf("Summarize() = %s with %d threads, want the last dump", summary.Host, summary.Threads)
+	}
+	if want := map[string]int{"BLOCKED": 2, "RUNNABLE": 2}; !reflect.DeepEqual(summary.States, want) {
+		t.Errorf("States = %v, want %v", summary.States, want)
+	}
+
+	var pools []string
+	for _, pool := range summary.Pools {
+		pools = append(pools, pool.Name)
+	}
+	if want := []string{"hybrisHTTP", "ImpExWorker", "main"}; !reflect.DeepEqual(pools, want) {
+		t.Errorf("Pools = %v, want blocked pools first: %v", pools, want)
+	}
+
+	if len(summary.Stacks) != 2 {
+		t.Fatalf("Stacks = %+v, want 2 groups", summary.Stacks)
+	}
+	blocked := summary.Stacks[0]
+	if !reflect.DeepEqual(blocked.Frames, stuck[:2]) {
+		t.Errorf("Frames = %v, want the top 2 frames", blocked.Frames)
+	}
+	if blocked.Unchanged != 2 {
+		t.Errorf("Unchanged = %d, want both threads stuck across dumps", blocked.Unchanged)
+	}
+	if summary.Stacks[1].Unchanged != 0 {
+		t.Errorf("Unchanged = %d, want the moving stack to count none", summary.Stacks[1].Unchanged)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Salvadego/HacTools/internal/options"
 	"github.com/Salvadego/HacTools/models"
 	"github.com/spf13/cobra"
 )
@@ -52,6 +53,19 @@ func OpenEditor(initialContent string, filePattern string) (string, error) {
 	return string(content), nil
 }
 
+// Template returns the user template name from the templates directory of
+// the config dir, or fallback when there is none.
+func Template(name, fallback string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(options.ConfigDir(), "templates", name))
+	if os.IsNotExist(err) {
+		return fallback, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	return string(content), nil
+}
+
 func CreateEditorCommand(opts models.EditorConfig) *cobra.Command {
 	var savePath string
 	
@@ -63,7 +77,15 @@ The editor is determined by the $EDITOR environment variable. If not set, vi wil
 You can optionally provide a template file as an argument.`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			initialContent := opts.InitialContent
+			filePattern, initialContent := opts.FilePattern, opts.InitialContent
+			if opts.TemplateFunc != nil {
+				var err error
+				filePattern, initialContent, err = opts.TemplateFunc()
+				if err != nil {
+					return err
+				}
+			}
+
 			if len(args) > 0 {
 				templatePath := args[0]
 				content, err := os.ReadFile(templatePath)
@@ -73,7 +95,7 @@ You can optionally provide a template file as an argument.`,
 				initialContent = string(content)
 			}
 
-			content, err := OpenEditor(initialContent, opts.FilePattern)
+			content, err := OpenEditor(initialContent, filePattern)
 			if err != nil {
 				return fmt.Errorf("editor error: %w", err)
 			}
@@ -82,6 +104,11 @@ You can optionally provide a template file as an argument.`,
 				return fmt.Errorf("content cannot be empty")
 			}
 
+			// Saving the starter template unchanged must not run its sample.
+			if len(args) == 0 && strings.TrimSpace(content) == strings.TrimSpace(initialContent) {
+				return fmt.Errorf("content is unchanged from the template, aborting")
+			}
+
 			if savePath != "" {
 				saveDir := filepath.Dir(savePath)
 				if _, err := os.Stat(saveDir); os.IsNotExist(err) {
@@ -0,0 +1,85 @@
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// timeout bounds how long Acquire waits for another process.
+	timeout = 5 * time.Second
+	// stale is the age after which a lock without a readable holder is
+	// assumed to be left behind by a process killed while taking it.
+	stale = 30 * time.Second
+	retry = 10 * time.Millisecond
+)
+
+// Acquire takes an exclusive lock on path by creating path.lock with the
+// process id, waiting while another process holds it. A lock whose holder
+// is no longer running is taken over. The returned function releases the
+// lock.
+func Acquire(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = file.WriteString(strconv.Itoa(os.Getpid()))
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+			}
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if abandoned(lockPath) {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(retry)
+	}
+}
+
+// abandoned reports whether the process holding the lock at lockPath is
+// gone. Without a readable process id, e.g. while the holder is still
+// writing it, only an old lock counts as abandoned.
+func abandoned(lockPath string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil {
+		return false
+	}
+
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return time.Since(info.ModTime()) > stale
+	}
+	return !running(pid)
+}
+
+func running(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
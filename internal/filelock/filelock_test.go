@@ -0,0 +1,46 @@
+package filelock
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAbandoned(t *testing.T) {
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skip("no process to wait for:", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		age     time.Duration
+		want    bool
+	}{
+		{"running holder", strconv.Itoa(os.Getpid()), time.Hour, false},
+		{"exited holder", strconv.Itoa(exited.Process.Pid), 0, true},
+		{"holder still writing", "", 0, false},
+		{"old lock without holder", "", time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.jsonl.lock")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			modified := time.Now().Add(-tt.age)
+			if err := os.Chtimes(path, modified, modified); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := abandoned(path); got != tt.want {
+				t.Errorf("abandoned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package flexsearch
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+)
+
+var typeCodePattern = regexp.MustCompile(`(?i)\bFROM\s*\{+\s*([A-Za-z0-9_]+)`)
+
+// ResolveColumns applies the --columns, --exclude and --no-blacklist flags on
+// top of the configured column rules.
+func ResolveColumns(base models.ColumnConfig, include, exclude []string, noBlacklist bool) models.ColumnConfig {
+	columns := models.ColumnConfig{
+		Blacklist: base.Blacklist,
+		Whitelist: base.Whitelist,
+		Rename:    base.Rename,
+		Types:     base.Types,
+	}
+
+	if noBlacklist {
+		columns.Blacklist = nil
+		columns.Types = make(map[string]models.ColumnConfig, len(base.Types))
+		for typeCode, rules := range base.Types {
+			rules.Blacklist = nil
+			columns.Types[typeCode] = rules
+		}
+	}
+
+	if len(include) > 0 {
+		columns.Whitelist = include
+	}
+	columns.Blacklist = append(append([]string{}, columns.Blacklist...), exclude...)
+
+	return columns
+}
+
+// SplitPatterns splits the comma separated column patterns of the --columns
+// and --exclude values. Commas inside a /regex/ or a [class] do not separate
+// patterns, so that /^a{1,3}$/ stays whole.
+func SplitPatterns(values []string) []string {
+	var patterns []string
+	add := func(pattern string) {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	for _, value := range values {
+		start, inRegex, inClass := 0, false, false
+		for i := 0; i < len(value); i++ {
+			switch c := value[i]; {
+			case c == '\\':
+				i++
+			case c == '/' && (inRegex || strings.TrimSpace(value[start:i]) == ""):
+				inRegex = !inRegex
+			case c == '[':
+				inClass = true
+			case c == ']':
+				inClass = false
+			case c == ',' && !inRegex && !inClass:
+				add(value[start:i])
+				start = i + 1
+			}
+		}
+		add(value[start:])
+	}
+	return patterns
+}
+
+// QueryTypeCode returns the first type of the FROM clause of a query.
+func QueryTypeCode(query string) string {
+	matches := typeCodePattern.FindStringSubmatch(query)
+	if len(matches) < 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// applyColumns drops blacklisted, non-whitelisted and (unless keepEmpty)
+// empty columns, orders the remaining ones by the whitelist and renames the
+// headers.
+func applyColumns(result *models.FlexSearchResponse, columns models.ColumnConfig, typeCode string, keepEmpty bool) {
+	validColumns, headers := selectColumns(result.Headers, result.ResultList, columns, typeCode, keepEmpty)
+	result.Headers = headers
+
+	for rowIdx, row := range result.ResultList {
+		result.ResultList[rowIdx] = pickColumns(row, validColumns)
+	}
+}
+
+// selectColumns returns the indices of the columns to keep, in output order,
+// together with their (renamed) headers.
+func selectColumns(headers []string, rows [][]string, columns models.ColumnConfig, typeCode string, keepEmpty bool) ([]int, []string) {
+	blacklist := columns.Blacklist
+	whitelist := columns.Whitelist
+	rename := make(map[string]string)
+	for from, to := range columns.Rename {
+		rename[strings.ToLower(from)] = to
+	}
+
+	for code, rules := range columns.Types {
+		if !strings.EqualFold(code, typeCode) {
+			continue
+		}
+		blacklist = append(append([]string{}, blacklist...), rules.Blacklist...)
+		if len(rules.Whitelist) > 0 {
+			whitelist = rules.Whitelist
+		}
+		for from, to := range rules.Rename {
+			rename[strings.ToLower(from)] = to
+		}
+	}
+
+	validColumns := make([]int, 0, len(headers))
+	for colIdx, header := range headers {
+		if matchesAny(header, blacklist) >= 0 {
+			continue
+		}
+		if len(whitelist) > 0 && matchesAny(header, whitelist) < 0 {
+			continue
+		}
+		if !keepEmpty && len(rows) > 0 && isEmptyColumn(rows, colIdx) {
+			continue
+		}
+		validColumns = append(validColumns, colIdx)
+	}
+
+	if len(whitelist) > 0 {
+		sort.SliceStable(validColumns, func(i, j int) bool {
+			return matchesAny(headers[validColumns[i]], whitelist) < matchesAny(headers[validColumns[j]], whitelist)
+		})
+	}
+
+	newHeaders := make([]string, len(validColumns))
+	for newIdx, oldIdx := range validColumns {
+		header := headers[oldIdx]
+		if to, ok := rename[strings.ToLower(normalizeHeader(header))]; ok {
+			header = to
+		}
+		newHeaders[newIdx] = header
+	}
+
+	return validColumns, newHeaders
+}
+
+func pickColumns(row []string, columns []int) []string {
+	picked := make([]string, len(columns))
+	for newIdx, oldIdx := range columns {
+		if oldIdx < len(row) {
+			picked[newIdx] = row[oldIdx]
+		}
+	}
+	return picked
+}
+
+func isEmptyColumn(rows [][]string, colIdx int) bool {
+	for _, row := range rows {
+		if colIdx < len(row) && !isEmptyCell(row[colIdx]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny returns the index of the first pattern matching header, or -1.
+// Patterns are case-insensitive globs, or regular expressions when enclosed
+// in slashes. Headers are matched with and without their p_ prefix.
+func matchesAny(header string, patterns []string) int {
+	candidates := []string{strings.ToLower(normalizeHeader(header)), strings.ToLower(header)}
+
+	for i, pattern := range patterns {
+		if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+			if err != nil {
+				logger.Error("Invalid column pattern %s: %v", pattern, err)
+				continue
+			}
+			for _, candidate := range candidates {
+				if re.MatchString(candidate) {
+					return i
+				}
+			}
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(strings.ToLower(pattern), candidate); ok {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func normalizeHeader(header string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(header, "p_"), "P_")
+}
@@ -0,0 +1,112 @@
+package flexsearch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestSplitPatterns(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"comma separated", []string{"code,name, catalogVersion"}, []string{"code", "name", "catalogVersion"}},
+		{"repeated", []string{"code", "name"}, []string{"code", "name"}},
+		{"regex with a quantifier", []string{"/^a{1,3}$/,code"}, []string{"/^a{1,3}$/", "code"}},
+		{"regex with an escaped slash", []string{`/a\/b,c/`}, []string{`/a\/b,c/`}},
+		{"glob class", []string{"p_[a,b]*,code"}, []string{"p_[a,b]*", "code"}},
+		{"slash inside a glob", []string{"a/b,c"}, []string{"a/b", "c"}},
+		{"empty values", []string{",code,,", ""}, []string{"code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitPatterns(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitPatterns() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		patterns []string
+		want     int
+	}{
+		{"exact", "p_code", []string{"name", "code"}, 1},
+		{"case insensitive", "P_CODE", []string{"code"}, 0},
+		{"prefixed pattern", "p_code", []string{"p_code"}, 0},
+		{"glob", "p_modifiedtime", []string{"*time"}, 0},
+		{"regex", "p_owner", []string{"/^own/"}, 0},
+		{"regex with a quantifier", "aa", []string{"/^a{1,3}$/"}, 0},
+		{"invalid regex is skipped", "code", []string{"/(/", "code"}, 1},
+		{"no match", "code", []string{"name"}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesAny(tt.header, tt.patterns); got != tt.want {
+				t.Errorf("matchesAny() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	headers := []string{"p_pk", "p_code", "p_name", "p_owner", "p_empty"}
+	rows := [][]string{{"1", "A", "Alpha", "x", ""}, {"2", "B", "Beta", "y", ""}}
+
+	tests := []struct {
+		name        string
+		columns     models.ColumnConfig
+		typeCode    string
+		keepEmpty   bool
+		wantHeaders []string
+	}{
+		{"drops empty columns", models.ColumnConfig{}, "Product", false, []string{"p_pk", "p_code", "p_name", "p_owner"}},
+		{"keeps empty columns", models.ColumnConfig{}, "Product", true, headers},
+		{"blacklist", models.ColumnConfig{Blacklist: []string{"pk", "/^own/"}}, "Product", false, []string{"p_code", "p_name"}},
+		{"whitelist order", models.ColumnConfig{Whitelist: []string{"name", "code"}}, "Product", false, []string{"p_name", "p_code"}},
+		{"rename", models.ColumnConfig{Whitelist: []string{"code"}, Rename: map[string]string{"code": "Code"}}, "Product", false, []string{"Code"}},
+		{"type rules", models.ColumnConfig{Types: map[string]models.ColumnConfig{"product": {Blacklist: []string{"owner", "pk"}}}}, "Product", false, []string{"p_code", "p_name"}},
+		{"rules of other types", models.ColumnConfig{Types: map[string]models.ColumnConfig{"Order": {Blacklist: []string{"*"}}}}, "Product", false, []string{"p_pk", "p_code", "p_name", "p_owner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := selectColumns(headers, rows, tt.columns, tt.typeCode, tt.keepEmpty)
+			if !reflect.DeepEqual(got, tt.wantHeaders) {
+				t.Errorf("selectColumns() headers = %q, want %q", got, tt.wantHeaders)
+			}
+		})
+	}
+}
+
+func TestResolveColumns(t *testing.T) {
+	base := models.ColumnConfig{
+		Blacklist: []string{"pk"},
+		Whitelist: []string{"code"},
+		Types:     map[string]models.ColumnConfig{"Product": {Blacklist: []string{"owner"}}},
+	}
+
+	got := ResolveColumns(base, []string{"name"}, []string{"/time$/"}, false)
+	if !reflect.DeepEqual(got.Whitelist, []string{"name"}) {
+		t.Errorf("Whitelist = %q, want the --columns patterns", got.Whitelist)
+	}
+	if !reflect.DeepEqual(got.Blacklist, []string{"pk", "/time$/"}) {
+		t.Errorf("Blacklist = %q, want the configured and --exclude patterns", got.Blacklist)
+	}
+
+	got = ResolveColumns(base, nil, []string{"x"}, true)
+	if !reflect.DeepEqual(got.Blacklist, []string{"x"}) || got.Types["Product"].Blacklist != nil {
+		t.Errorf("--no-blacklist kept configured patterns: %q, %q", got.Blacklist, got.Types["Product"].Blacklist)
+	}
+	if len(base.Blacklist) != 1 {
+		t.Errorf("ResolveColumns changed the base config: %q", base.Blacklist)
+	}
+}
@@ -0,0 +1,68 @@
+package flexsearch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05.0",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"Mon Jan 02 15:04:05 MST 2006",
+}
+
+// CompareValues compares two cells numerically when both are numbers, by
+// date when both are dates and case-insensitively otherwise. Empty and null
+// cells sort first.
+func CompareValues(a, b string) int {
+	aEmpty, bEmpty := isEmptyCell(a), isEmptyCell(b)
+	switch {
+	case aEmpty && bEmpty:
+		return 0
+	case aEmpty:
+		return -1
+	case bEmpty:
+		return 1
+	}
+
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return compareOrdered(x, y)
+		}
+	}
+
+	if x, ok := parseDate(a); ok {
+		if y, ok := parseDate(b); ok {
+			return x.Compare(y)
+		}
+	}
+
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareOrdered(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isEmptyCell(s string) bool {
+	return s == "" || s == "null"
+}
@@ -0,0 +1,195 @@
+package flexsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const itemScript = `import de.hybris.platform.core.PK
+import de.hybris.platform.core.model.ItemModel
+import groovy.json.JsonOutput
+
+def item = modelService.get(PK.parse('%s'))
+def composedType = typeService.getComposedTypeForCode('%s')
+def languages = commonI18NService.getAllLanguages().sort { it.isocode }
+
+def render
+render = { value ->
+    if (value == null) return null
+    if (value instanceof ItemModel) return value.itemtype + '(' + value.pk + ')'
+    if (value instanceof Collection) return value.collect { render(it) }
+    if (value instanceof Map) return value.collectEntries { k, v -> [(String.valueOf(render(k))): render(v)] }
+    return String.valueOf(value)
+}
+
+def attributes = []
+typeService.getAttributeDescriptorsForType(composedType).sort { it.qualifier }.each { descriptor ->
+    try {
+        if (descriptor.localized) {
+            languages.each { language ->
+                def locale = commonI18NService.getLocaleForLanguage(language)
+                attributes << [qualifier: descriptor.qualifier, language: language.isocode,
+                               value: render(modelService.getAttributeValue(item, descriptor.qualifier, locale))]
+            }
+        } else {
+            attributes << [qualifier: descriptor.qualifier, value: render(modelService.getAttributeValue(item, descriptor.qualifier))]
+        }
+    } catch (Exception e) {
+        attributes << [qualifier: descriptor.qualifier, error: String.valueOf(e.message)]
+    }
+}
+
+return JsonOutput.toJson([type: item.itemtype, pk: item.pk.toString(), attributes: attributes])
+`
+
+const expandScript = `import de.hybris.platform.core.PK
+import de.hybris.platform.core.model.ItemModel
+import groovy.json.JsonOutput
+
+def summaries = [:]
+%s.each { pk ->
+    try {
+        def item = modelService.get(PK.parse(pk))
+        def composedType = typeService.getComposedTypeForCode(item.itemtype)
+        summaries[pk] = typeService.getAttributeDescriptorsForType(composedType)
+            .findAll { it.unique && !it.localized }
+            .sort { it.qualifier }
+            .collect { descriptor ->
+                def value = modelService.getAttributeValue(item, descriptor.qualifier)
+                if (value instanceof ItemModel) value = value.itemtype + '(' + value.pk + ')'
+                descriptor.qualifier + '=' + value
+            }
+            .join(', ')
+    } catch (Exception e) {
+        summaries[pk] = '<error: ' + String.valueOf(e.message) + '>'
+    }
+}
+
+return JsonOutput.toJson(summaries)
+`
+
+// FetchItem resolves the type of pk with the PK analyzer and reads every
+// attribute of the item through the scripting console. Localized attributes
+// are returned once per language.
+func (e *FlexSearchExecutor) FetchItem(pk string) (*models.ItemDetails, error) {
+	if !isPotentialPK(pk) {
+		return nil, fmt.Errorf("invalid PK: %s", pk)
+	}
+
+	pkInfo, err := e.Client.AnalyzePK(pk)
+	if err != nil {
+		return nil, err
+	}
+	if pkInfo == nil || pkInfo.ComposedTypeCode == "" {
+		return nil, fmt.Errorf("could not determine the type of PK %s", pk)
+	}
+
+	var item models.ItemDetails
+	if err := e.executeJSONScript(fmt.Sprintf(itemScript, pk, pkInfo.ComposedTypeCode), &item); err != nil {
+		return nil, fmt.Errorf("failed to fetch item %s: %w", pk, err)
+	}
+
+	return &item, nil
+}
+
+// ExpandReferences replaces the PK cells of result by a summary of the
+// unique attributes of the referenced items. A PK that cannot be read is
+// replaced by the error message, as attributes are in FetchItem.
+func (e *FlexSearchExecutor) ExpandReferences(result *models.FlexSearchResponse) error {
+	if len(result.PKTypes) == 0 {
+		return nil
+	}
+
+	pks := make([]string, 0, len(result.PKTypes))
+	for pk := range result.PKTypes {
+		pks = append(pks, pk)
+	}
+
+	pkList, err := json.Marshal(pks)
+	if err != nil {
+		return fmt.Errorf("failed to encode PKs: %w", err)
+	}
+
+	summaries := make(map[string]string)
+	if err := e.executeJSONScript(fmt.Sprintf(expandScript, pkList), &summaries); err != nil {
+		return fmt.Errorf("failed to expand references: %w", err)
+	}
+
+	result.Expanded = summaries
+	return nil
+}
+
+func (e *FlexSearchExecutor) executeJSONScript(script string, target any) error {
+	resp, err := e.Client.ExecuteGroovy(map[string]any{
+		"script":     script,
+		"_csrf":      e.Client.Csrf,
+		"scriptType": "groovy",
+		"commit":     false,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.StacktraceText != "" {
+		return fmt.Errorf("script failed: %s", firstLine(resp.StacktraceText))
+	}
+
+	if err := json.Unmarshal([]byte(resp.ExecutionResult), target); err != nil {
+		return fmt.Errorf("failed to decode script result: %w, result: %s", err, resp.ExecutionResult)
+	}
+
+	return nil
+}
+
+func (e *FlexSearchExecutor) DisplayItem(item *models.ItemDetails) error {
+	if item == nil {
+		return fmt.Errorf("no item to display")
+	}
+
+	fmt.Printf("%s (%s)\n\n", item.Type, item.PK)
+
+	table := output.NewTable([]string{"Attribute", "Language", "Value"})
+	table.SetAutoWrapText(true)
+	table.SetColWidth(60)
+
+	for _, attribute := range item.Attributes {
+		value := formatValue(attribute.Value)
+		if attribute.Error != "" {
+			value = "<error: " + attribute.Error + ">"
+		}
+		table.Append([]string{attribute.Qualifier, attribute.Language, value})
+	}
+
+	table.Render()
+	return nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		parts := make([]string, len(v))
+		for i, element := range v {
+			parts[i] = formatValue(element)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,103 @@
+package flexsearch
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Profile executes query runs times without PK analysis or caching and
+// records the round trip and server execution time of every run.
+func (e *FlexSearchExecutor) Profile(query string, runs int, opts models.FlexExecuteOptions) (*models.FlexProfile, error) {
+	if runs <= 0 {
+		return nil, fmt.Errorf("runs must be positive")
+	}
+	if opts.Locale == "" {
+		opts.Locale = "en"
+	}
+
+	if err := e.Client.EnsureLoggedIn(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	profile := &models.FlexProfile{}
+	for run := 1; run <= runs; run++ {
+		data := map[string]any{
+			"flexibleSearchQuery": query,
+			"_csrf":               e.Client.Csrf,
+			"maxCount":            opts.MaxCount,
+			"user":                e.Client.Username,
+			"locale":              opts.Locale,
+			"commit":              false,
+		}
+
+		start := time.Now()
+		resp, err := e.Client.ExecuteFlexSearch(data)
+		if err != nil {
+			return nil, fmt.Errorf("run %d failed: %w", run, err)
+		}
+		roundTrip := time.Since(start)
+
+		if resp.Exception != nil {
+			return nil, fmt.Errorf("flex search error: %s", resp.Exception.Message)
+		}
+
+		logger.Info("Run %d/%d took %s (server %dms)", run, runs, roundTrip, resp.ExecutionTime)
+
+		profile.RoundTrips = append(profile.RoundTrips, roundTrip)
+		profile.ServerTimes = append(profile.ServerTimes, time.Duration(resp.ExecutionTime)*time.Millisecond)
+		profile.Rows = len(resp.ResultList)
+		profile.TranslatedSQL = resp.Query
+	}
+
+	return profile, nil
+}
+
+func (e *FlexSearchExecutor) DisplayProfile(profile *models.FlexProfile) error {
+	if profile == nil {
+		return fmt.Errorf("no profile to display")
+	}
+
+	table := output.NewTable([]string{"Metric", "Min", "Median", "P95", "Max"})
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+
+	for _, metric := range []struct {
+		name      string
+		durations []time.Duration
+	}{
+		{"round trip", profile.RoundTrips},
+		{"server execution", profile.ServerTimes},
+	} {
+		sorted := slices.Clone(metric.durations)
+		slices.Sort(sorted)
+		table.Append([]string{
+			metric.name,
+			sorted[0].Round(time.Millisecond).String(),
+			percentile(sorted, 50).Round(time.Millisecond).String(),
+			percentile(sorted, 95).Round(time.Millisecond).String(),
+			sorted[len(sorted)-1].Round(time.Millisecond).String(),
+		})
+	}
+
+	table.Render()
+
+	fmt.Printf("\nRuns: %d, rows per run: %d\n", len(profile.RoundTrips), profile.Rows)
+	if profile.TranslatedSQL != "" {
+		fmt.Println("\n=== TRANSLATED SQL ===")
+		fmt.Println(profile.TranslatedSQL)
+	}
+
+	return nil
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
@@ -1,82 +1,178 @@
 package flexsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"html"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/Salvadego/HacTools/internal/cache"
 	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/logger"
 	"github.com/Salvadego/HacTools/models"
 	"github.com/olekukonko/tablewriter"
 )
 
 type FlexSearchExecutor struct {
 	Client *client.HACClient
+	Cache  *cache.Cache
+}
+
+type cachedResult struct {
+	Headers    []string          `json:"headers"`
+	ResultList [][]string        `json:"resultList"`
+	PKTypes    map[string]string `json:"pkTypes"`
+	Expanded   map[string]string `json:"expanded"`
 }
 
 func NewFlexSearchExecutor(client *client.HACClient) *FlexSearchExecutor {
 	executor := &FlexSearchExecutor{
 		Client: client,
+		Cache:  cache.New("flexsearch"),
 	}
 	return executor
 }
 
 func (e *FlexSearchExecutor) Execute(query string, opts models.FlexExecuteOptions) (*models.FlexSearchResponse, error) {
+	if opts.Locale == "" {
+		opts.Locale = "en"
+	}
+
+	var key string
+	if opts.CacheTTL > 0 {
+		key = e.cacheKey(query, opts)
+
+		var cached cachedResult
+		if e.Cache.Get(key, opts.CacheTTL, &cached) {
+			return &models.FlexSearchResponse{
+				Headers:    cached.Headers,
+				ResultList: cached.ResultList,
+				PKTypes:    cached.PKTypes,
+				Expanded:   cached.Expanded,
+				Cached:     true,
+			}, nil
+		}
+	}
+
+	if err := e.Client.EnsureLoggedIn(); err != nil {
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	// Queries always run without commit and never as raw SQL, so they need no
+	// confirmation on protected environments.
 	data := map[string]any{
 		"flexibleSearchQuery": query,
 		"_csrf":               e.Client.Csrf,
 		"maxCount":            opts.MaxCount,
 		"user":                e.Client.Username,
-		"locale":              "en",
+		"locale":              opts.Locale,
 		"commit":              false,
 	}
 
-	var blacklist []string
-	if !opts.NoBlacklist {
-		blacklist = opts.ColumnBlacklist
-	}
-
-	resp, err := e.Client.ExecuteFlexSearch(data, blacklist)
+	resp, err := e.Client.ExecuteFlexSearch(data)
 	if err != nil {
 		return nil, err
 	}
+	e.Client.Timings.Add("server execution", time.Duration(resp.ExecutionTime)*time.Millisecond)
+
+	applyColumns(resp, opts.Columns, QueryTypeCode(query), opts.KeepEmpty)
 
 	if !opts.NoAnalyze {
+		resp.PKTypes = e.analyzePKs(resp.ResultList)
 
-		var wg sync.WaitGroup
+		if opts.Expand {
+			if err := e.ExpandReferences(resp); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-		var mutex sync.Mutex
+	if resp.Exception != nil {
+		return nil, fmt.Errorf("flex search error: %s", resp.Exception.Message)
+	}
 
-		for rowIdx, row := range resp.ResultList {
-			wg.Add(1)
-			go func(rowIdx int, row []string) {
-				defer wg.Done()
+	if opts.CacheTTL > 0 {
+		err := e.Cache.Put(key, cachedResult{
+			Headers:    resp.Headers,
+			ResultList: resp.ResultList,
+			PKTypes:    resp.PKTypes,
+			Expanded:   resp.Expanded,
+		})
+		if err != nil {
+			logger.Error("Failed to cache result: %v", err)
+		}
+	}
 
-				for colIdx, cell := range row {
-					if isPotentialPK(cell) {
-						pkInfo, err := e.Client.AnalyzePK(cell)
-						if err == nil && pkInfo != nil && pkInfo.ComposedTypeCode != "" {
+	return resp, nil
+}
 
-							mutex.Lock()
-                            resp.ResultList[rowIdx][colIdx] = fmt.Sprintf("%s(%s)", pkInfo.ComposedTypeCode, cell[7:])
-							mutex.Unlock()
-						}
-					}
-				}
-			}(rowIdx, row)
-		}
+// cacheKey identifies a query by target, query text, maxCount, locale and
+// every option that changes the processed result.
+func (e *FlexSearchExecutor) cacheKey(query string, opts models.FlexExecuteOptions) string {
+	opts.CacheTTL = 0
+	encodedOpts, _ := json.Marshal(opts)
+
+	return cache.Key(
+		e.Client.BaseURL,
+		e.Client.Username,
+		query,
+		strconv.Itoa(opts.MaxCount),
+		opts.Locale,
+		string(encodedOpts),
+	)
+}
+
+// analyzePKs resolves the composed type of every distinct PK-like cell.
+func (e *FlexSearchExecutor) analyzePKs(rows [][]string) map[string]string {
+	defer e.Client.Timings.Track("pk analysis", time.Now())
+
+	pkTypes := make(map[string]string)
 
-		wg.Wait()
+	pks := make(map[string]bool)
+	for _, row := range rows {
+		for _, cell := range row {
+			if isPotentialPK(cell) {
+				pks[cell] = true
+			}
+		}
 	}
 
-	if resp.Exception != nil {
-		return nil, fmt.Errorf("flex search error: %s", resp.Exception.Message)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for pk := range pks {
+		wg.Add(1)
+		go func(pk string) {
+			defer wg.Done()
+
+			pkInfo, err := e.Client.AnalyzePK(pk)
+			if err == nil && pkInfo != nil && pkInfo.ComposedTypeCode != "" {
+				mutex.Lock()
+				pkTypes[pk] = pkInfo.ComposedTypeCode
+				mutex.Unlock()
+			}
+		}(pk)
 	}
 
-	return resp, nil
+	wg.Wait()
+	return pkTypes
+}
+
+// FormatCell returns the display text of a raw cell value, rendering analyzed
+// PKs as Type(…) or, when references were expanded, as Type(attributes).
+func FormatCell(result *models.FlexSearchResponse, cell string) string {
+	if typeCode, ok := result.PKTypes[cell]; ok {
+		if summary, ok := result.Expanded[cell]; ok && summary != "" {
+			return fmt.Sprintf("%s(%s)", typeCode, summary)
+		}
+		return fmt.Sprintf("%s(%s)", typeCode, cell[7:])
+	}
+	return html.UnescapeString(cell)
 }
 
 func (e *FlexSearchExecutor) formatTable(result *models.FlexSearchResponse) string {
@@ -95,7 +191,7 @@ func (e *FlexSearchExecutor) formatTable(result *models.FlexSearchResponse) stri
 
 	headers := make([]string, len(result.Headers))
 	for i, h := range result.Headers {
-		headers[i] = strings.TrimPrefix(strings.TrimPrefix(h, "p_"), "P_")
+		headers[i] = normalizeHeader(h)
 	}
 	table.SetHeader(headers)
 
@@ -104,11 +200,7 @@ func (e *FlexSearchExecutor) formatTable(result *models.FlexSearchResponse) stri
 	for _, row := range result.ResultList {
 		tableRow := make([]string, len(row))
 		for i, cell := range row {
-			if cell != "" {
-				tableRow[i] = html.UnescapeString(cell)
-			} else {
-				tableRow[i] = ""
-			}
+			tableRow[i] = FormatCell(result, cell)
 		}
 		table.Append(tableRow)
 	}
@@ -133,7 +225,7 @@ func (e *FlexSearchExecutor) DisplayResults(result *models.FlexSearchResponse) e
 
 	tableOutput := e.formatTable(result)
 
-	if isPipe() {
+	if IsPipe() {
 		fmt.Print(tableOutput)
 		return nil
 	}
@@ -142,35 +234,21 @@ func (e *FlexSearchExecutor) DisplayResults(result *models.FlexSearchResponse) e
 }
 
 func (e *FlexSearchExecutor) displayWithPager(content string) error {
-	tmpfile, err := os.CreateTemp("", "flexsearch-*.txt")
-	fmt.Println(tmpfile.Name())
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-
-	if _, err := tmpfile.WriteString(content); err != nil {
-		return fmt.Errorf("failed to write to temp file: %w", err)
-	}
-
-	if err := tmpfile.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file: %w", err)
-	}
-
 	pagerCmd := os.Getenv("PAGER")
 	if pagerCmd == "" {
 		pagerCmd = "less -RS"
 	}
 	args := strings.Fields(pagerCmd)
-	args = append(args, tmpfile.Name())
 
 	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(content)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	return cmd.Run()
 }
 
-func isPipe() bool {
+func IsPipe() bool {
 	fi, _ := os.Stdout.Stat()
 	return fi.Mode()&os.ModeCharDevice == 0
 }
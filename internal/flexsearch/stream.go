@@ -0,0 +1,160 @@
+package flexsearch
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/models"
+)
+
+// streamScript runs one page of the query through the FlexibleSearchService
+// and returns the number of rows on the first line, followed by the rows as
+// CSV. Every column is read as a String so that PKs are exported verbatim.
+// Empty values are quoted so that a row of a single empty column is not a
+// blank line, which CSV readers skip.
+const streamScript = `import de.hybris.platform.servicelayer.search.FlexibleSearchQuery
+
+def query = new FlexibleSearchQuery(new String('%s'.decodeBase64(), 'UTF-8'))
+query.setStart(%d)
+query.setCount(%d)
+query.setNeedTotal(false)
+query.setResultClassList([String] * %d)
+query.setLocale(new Locale('%s'))
+
+def escape = { value ->
+    def text = value == null ? '' : String.valueOf(value)
+    if (text.isEmpty() || text.contains(',') || text.contains('"') || text.contains('\n') || text.contains('\r')) {
+        return '"' + text.replace('"', '""') + '"'
+    }
+    return text
+}
+
+def rows = flexibleSearchService.search(query).result
+def out = new StringBuilder()
+out.append(rows.size()).append('\n')
+rows.each { row ->
+    def values = row instanceof List ? row : [row]
+    out.append(values.collect { escape(it) }.join(',')).append('\n')
+}
+return out.toString()
+`
+
+// Stream exports every row of query as CSV to w. The query is first probed
+// through the flexsearch console to learn its columns, then read page by page
+// with a generated Groovy script, so the result is not limited by maxCount.
+// Queries should have a stable ORDER BY for the pages to be consistent.
+func (e *FlexSearchExecutor) Stream(query string, w io.Writer, opts models.FlexStreamOptions) (int, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 10000
+	}
+	if opts.Locale == "" {
+		opts.Locale = "en"
+	}
+
+	if err := e.Client.EnsureLoggedIn(); err != nil {
+		return 0, fmt.Errorf("failed to login: %w", err)
+	}
+
+	probe, err := e.Client.ExecuteFlexSearch(map[string]any{
+		"flexibleSearchQuery": query,
+		"_csrf":               e.Client.Csrf,
+		"maxCount":            1,
+		"user":                e.Client.Username,
+		"locale":              opts.Locale,
+		"commit":              false,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if probe.Exception != nil {
+		return 0, fmt.Errorf("flex search error: %s", probe.Exception.Message)
+	}
+
+	columns, headers := selectColumns(probe.Headers, nil, opts.Columns, QueryTypeCode(query), true)
+	for i, header := range headers {
+		headers[i] = normalizeHeader(header)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return 0, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	encodedQuery := base64.StdEncoding.EncodeToString([]byte(query))
+	total := 0
+	for {
+		script := fmt.Sprintf(streamScript, encodedQuery, total, opts.PageSize, len(probe.Headers), opts.Locale)
+
+		count, rows, err := e.fetchChunk(script, len(probe.Headers))
+		if err != nil {
+			return total, fmt.Errorf("failed to fetch rows %d-%d: %w", total, total+opts.PageSize, err)
+		}
+
+		for _, row := range rows {
+			if err := writer.Write(pickColumns(row, columns)); err != nil {
+				return total, fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return total, fmt.Errorf("failed to write CSV: %w", err)
+		}
+
+		total += count
+		logger.Info("Exported %d rows", total)
+		if opts.OnProgress != nil {
+			opts.OnProgress(total)
+		}
+
+		if count < opts.PageSize {
+			return total, nil
+		}
+	}
+}
+
+func (e *FlexSearchExecutor) fetchChunk(script string, fields int) (int, [][]string, error) {
+	resp, err := e.Client.ExecuteGroovy(map[string]any{
+		"script":     script,
+		"_csrf":      e.Client.Csrf,
+		"scriptType": "groovy",
+		"commit":     false,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if resp.StacktraceText != "" {
+		return 0, nil, fmt.Errorf("script failed: %s", firstLine(resp.StacktraceText))
+	}
+
+	return parseChunk(resp.ExecutionResult, fields)
+}
+
+// parseChunk reads the row count and the CSV rows of a page written by
+// streamScript. Every row must have fields columns, and the number of rows
+// must match the count, so that no row is lost silently.
+func parseChunk(result string, fields int) (int, [][]string, error) {
+	countLine, data, _ := strings.Cut(result, "\n")
+	count, err := strconv.Atoi(strings.TrimSpace(countLine))
+	if err != nil {
+		return 0, nil, fmt.Errorf("unexpected chunk header %q", countLine)
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = fields
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to parse chunk: %w", err)
+	}
+
+	if len(rows) != count {
+		return 0, nil, fmt.Errorf("chunk has %d rows, expected %d", len(rows), count)
+	}
+
+	return count, rows, nil
+}
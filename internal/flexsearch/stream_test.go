@@ -0,0 +1,37 @@
+package flexsearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChunk(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    string
+		fields    int
+		wantCount int
+		wantRows  [][]string
+		wantErr   bool
+	}{
+		{"empty page", "0\n", 1, 0, nil, false},
+		{"rows", "2\na,1\nb,2\n", 2, 2, [][]string{{"a", "1"}, {"b", "2"}}, false},
+		{"null values of a single column", "3\na\n\"\"\nc\n", 1, 3, [][]string{{"a"}, {""}, {"c"}}, false},
+		{"quoted separators", "1\n\"a,b\",\"say \"\"hi\"\"\",\"x\ny\"\n", 3, 1, [][]string{{"a,b", `say "hi"`, "x\ny"}}, false},
+		{"blank line loses a row", "3\na\n\nb\n", 1, 0, nil, true},
+		{"wrong number of fields", "1\na,b\n", 1, 0, nil, true},
+		{"bad header", "rows\na\n", 1, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, rows, err := parseChunk(tt.result, tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseChunk() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if count != tt.wantCount || !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("parseChunk() = %d, %q, want %d, %q", count, rows, tt.wantCount, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package flexsearch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+var (
+	conditionPattern   = regexp.MustCompile(`^\s*(.+?)\s*(!~|~|!=|<=|>=|=|<|>)\s*(.*?)\s*$`)
+	aggregationPattern = regexp.MustCompile(`^\s*(count|sum|avg|min|max)\s*(?:\(\s*(.*?)\s*\))?\s*$`)
+)
+
+type condition struct {
+	column   int
+	operator string
+	value    string
+	pattern  *regexp.Regexp
+}
+
+type aggregation struct {
+	name     string
+	function string
+	column   int
+}
+
+type sortKey struct {
+	column int
+	desc   bool
+}
+
+// Transform filters, groups, aggregates and sorts the rows of result on the
+// client, in that order.
+func Transform(result *models.FlexSearchResponse, opts models.FlexTransformOptions) error {
+	if err := filterRows(result, opts.Where); err != nil {
+		return err
+	}
+
+	if len(opts.GroupBy) > 0 || len(opts.Agg) > 0 {
+		if err := aggregateRows(result, opts.GroupBy, opts.Agg); err != nil {
+			return err
+		}
+	}
+
+	return sortRows(result, opts.Sort)
+}
+
+func filterRows(result *models.FlexSearchResponse, where []string) error {
+	conditions := make([]condition, 0, len(where))
+	for _, expression := range where {
+		cond, err := parseCondition(result.Headers, expression)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	rows := result.ResultList[:0]
+	for _, row := range result.ResultList {
+		if matchesConditions(row, conditions) {
+			rows = append(rows, row)
+		}
+	}
+	result.ResultList = rows
+
+	return nil
+}
+
+func parseCondition(headers []string, expression string) (condition, error) {
+	matches := conditionPattern.FindStringSubmatch(expression)
+	if matches == nil {
+		return condition{}, fmt.Errorf("invalid condition %q (expected column<op>value with op one of ~ !~ = != < <= > >=)", expression)
+	}
+
+	column, err := columnIndex(headers, matches[1])
+	if err != nil {
+		return condition{}, err
+	}
+
+	cond := condition{column: column, operator: matches[2], value: matches[3]}
+	if cond.operator == "~" || cond.operator == "!~" {
+		cond.pattern, err = regexp.Compile(cond.value)
+		if err != nil {
+			return condition{}, fmt.Errorf("invalid regex in condition %q: %w", expression, err)
+		}
+	}
+
+	return cond, nil
+}
+
+func matchesConditions(row []string, conditions []condition) bool {
+	for _, cond := range conditions {
+		cell := row[cond.column]
+
+		var ok bool
+		switch cond.operator {
+		case "~":
+			ok = cond.pattern.MatchString(cell)
+		case "!~":
+			ok = !cond.pattern.MatchString(cell)
+		case "=":
+			ok = CompareValues(cell, cond.value) == 0
+		case "!=":
+			ok = CompareValues(cell, cond.value) != 0
+		case "<":
+			ok = CompareValues(cell, cond.value) < 0
+		case "<=":
+			ok = CompareValues(cell, cond.value) <= 0
+		case ">":
+			ok = CompareValues(cell, cond.value) > 0
+		case ">=":
+			ok = CompareValues(cell, cond.value) >= 0
+		}
+
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func aggregateRows(result *models.FlexSearchResponse, groupBy, agg []string) error {
+	groupColumns := make([]int, len(groupBy))
+	for i, name := range groupBy {
+		column, err := columnIndex(result.Headers, name)
+		if err != nil {
+			return err
+		}
+		groupColumns[i] = column
+	}
+
+	if len(agg) == 0 {
+		agg = []string{"count"}
+	}
+
+	aggregations := make([]aggregation, len(agg))
+	for i, expression := range agg {
+		matches := aggregationPattern.FindStringSubmatch(strings.ToLower(expression))
+		if matches == nil {
+			return fmt.Errorf("invalid aggregation %q (expected count, sum(col), avg(col), min(col) or max(col))", expression)
+		}
+
+		aggregations[i] = aggregation{name: strings.TrimSpace(expression), function: matches[1], column: -1}
+		if matches[1] != "count" || matches[2] != "" {
+			if matches[2] == "" {
+				return fmt.Errorf("aggregation %q requires a column", expression)
+			}
+			column, err := columnIndex(result.Headers, matches[2])
+			if err != nil {
+				return err
+			}
+			aggregations[i].column = column
+		}
+	}
+
+	var order []string
+	groups := make(map[string][][]string)
+	for _, row := range result.ResultList {
+		values := make([]string, len(groupColumns))
+		for i, column := range groupColumns {
+			values[i] = row[column]
+		}
+
+		key := strings.Join(values, "\x00")
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+
+	headers := make([]string, 0, len(groupColumns)+len(aggregations))
+	for _, column := range groupColumns {
+		headers = append(headers, result.Headers[column])
+	}
+	for _, a := range aggregations {
+		headers = append(headers, a.name)
+	}
+
+	rows := make([][]string, 0, len(order))
+	for _, key := range order {
+		groupRows := groups[key]
+
+		row := make([]string, 0, len(headers))
+		for _, column := range groupColumns {
+			row = append(row, groupRows[0][column])
+		}
+		for _, a := range aggregations {
+			row = append(row, a.apply(groupRows))
+		}
+		rows = append(rows, row)
+	}
+
+	result.Headers = headers
+	result.ResultList = rows
+	return nil
+}
+
+func (a aggregation) apply(rows [][]string) string {
+	if a.function == "count" {
+		if a.column < 0 {
+			return strconv.Itoa(len(rows))
+		}
+
+		count := 0
+		for _, row := range rows {
+			if !isEmptyCell(row[a.column]) {
+				count++
+			}
+		}
+		return strconv.Itoa(count)
+	}
+
+	switch a.function {
+	case "min", "max":
+		var best string
+		for _, row := range rows {
+			cell := row[a.column]
+			if isEmptyCell(cell) {
+				continue
+			}
+			cmp := CompareValues(cell, best)
+			if best == "" || (a.function == "min" && cmp < 0) || (a.function == "max" && cmp > 0) {
+				best = cell
+			}
+		}
+		return best
+	default:
+		sum, count := 0.0, 0
+		for _, row := range rows {
+			if value, err := strconv.ParseFloat(row[a.column], 64); err == nil {
+				sum += value
+				count++
+			}
+		}
+
+		if a.function == "avg" {
+			if count == 0 {
+				return ""
+			}
+			sum /= float64(count)
+		}
+		return strconv.FormatFloat(sum, 'f', -1, 64)
+	}
+}
+
+func sortRows(result *models.FlexSearchResponse, specs []string) error {
+	keys := make([]sortKey, 0, len(specs))
+	for _, spec := range specs {
+		name, direction, _ := strings.Cut(spec, ":")
+
+		column, err := columnIndex(result.Headers, name)
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(direction) {
+		case "", "asc":
+			keys = append(keys, sortKey{column: column})
+		case "desc":
+			keys = append(keys, sortKey{column: column, desc: true})
+		default:
+			return fmt.Errorf("invalid sort direction %q (expected asc or desc)", direction)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(result.ResultList, func(i, j int) bool {
+		for _, key := range keys {
+			cmp := CompareValues(result.ResultList[i][key.column], result.ResultList[j][key.column])
+			if cmp == 0 {
+				continue
+			}
+			if key.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	return nil
+}
+
+// columnIndex finds a column by name, ignoring case and the p_ prefix.
+func columnIndex(headers []string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	for i, header := range headers {
+		if strings.EqualFold(header, name) || strings.EqualFold(normalizeHeader(header), normalizeHeader(name)) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q", name)
+}
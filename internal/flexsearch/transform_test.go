@@ -0,0 +1,115 @@
+package flexsearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestParseCondition(t *testing.T) {
+	headers := []string{"p_code", "price", "name"}
+
+	tests := []struct {
+		expression string
+		column     int
+		operator   string
+		value      string
+		wantErr    string
+	}{
+		{"code=A1", 0, "=", "A1", ""},
+		{"p_code = A1", 0, "=", "A1", ""},
+		{"price>=10", 1, ">=", "10", ""},
+		{"price <= 10", 1, "<=", "10", ""},
+		{"price!=10", 1, "!=", "10", ""},
+		{"name~^Cam", 2, "~", "^Cam", ""},
+		{"name!~era$", 2, "!~", "era$", ""},
+		{"name=a=b", 2, "=", "a=b", ""},
+		{"name=", 2, "=", "", ""},
+		{"name", 0, "", "", "invalid condition"},
+		{"other=1", 0, "", "", `unknown column "other"`},
+		{"name~(", 0, "", "", "invalid regex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, err := parseCondition(headers, tt.expression)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseCondition() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCondition() error = %v", err)
+			}
+			if got.column != tt.column || got.operator != tt.operator || got.value != tt.value {
+				t.Errorf("parseCondition() = %d %q %q, want %d %q %q", got.column, got.operator, got.value, tt.column, tt.operator, tt.value)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	rows := func() *models.FlexSearchResponse {
+		return &models.FlexSearchResponse{
+			Headers: []string{"code", "status", "price"},
+			ResultList: [][]string{
+				{"A", "NEW", "10"},
+				{"B", "DONE", "9"},
+				{"C", "NEW", "100"},
+				{"D", "DONE", "null"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		opts        models.FlexTransformOptions
+		wantHeaders []string
+		want        [][]string
+		wantErr     string
+	}{
+		{"numeric filter", models.FlexTransformOptions{Where: []string{"price>9"}},
+			[]string{"code", "status", "price"}, [][]string{{"A", "NEW", "10"}, {"C", "NEW", "100"}}, ""},
+		{"conditions are combined", models.FlexTransformOptions{Where: []string{"status=NEW", "price<50"}},
+			[]string{"code", "status", "price"}, [][]string{{"A", "NEW", "10"}}, ""},
+		{"regex filter", models.FlexTransformOptions{Where: []string{"code~^[BD]$"}},
+			[]string{"code", "status", "price"}, [][]string{{"B", "DONE", "9"}, {"D", "DONE", "null"}}, ""},
+		{"numeric sort descending", models.FlexTransformOptions{Sort: []string{"price:desc"}},
+			[]string{"code", "status", "price"}, [][]string{{"C", "NEW", "100"}, {"A", "NEW", "10"}, {"B", "DONE", "9"}, {"D", "DONE", "null"}}, ""},
+		{"group with default count", models.FlexTransformOptions{GroupBy: []string{"status"}},
+			[]string{"status", "count"}, [][]string{{"NEW", "2"}, {"DONE", "2"}}, ""},
+		{"aggregations", models.FlexTransformOptions{GroupBy: []string{"status"}, Agg: []string{"count(price)", "sum(price)", "avg(price)", "min(price)", "max(price)"}},
+			[]string{"status", "count(price)", "sum(price)", "avg(price)", "min(price)", "max(price)"},
+			[][]string{{"NEW", "2", "110", "55", "10", "100"}, {"DONE", "1", "9", "9", "9", "9"}}, ""},
+		{"aggregate then sort", models.FlexTransformOptions{GroupBy: []string{"status"}, Agg: []string{"sum(price)"}, Sort: []string{"sum(price)"}},
+			[]string{"status", "sum(price)"}, [][]string{{"DONE", "9"}, {"NEW", "110"}}, ""},
+		{"aggregation without column", models.FlexTransformOptions{Agg: []string{"sum"}}, nil, nil, "requires a column"},
+		{"unknown aggregation", models.FlexTransformOptions{Agg: []string{"median(price)"}}, nil, nil, "invalid aggregation"},
+		{"invalid sort direction", models.FlexTransformOptions{Sort: []string{"price:up"}}, nil, nil, "invalid sort direction"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rows()
+			err := Transform(result, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Transform() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transform() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", result.Headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(result.ResultList, tt.want) {
+				t.Errorf("rows = %q, want %q", result.ResultList, tt.want)
+			}
+		})
+	}
+}
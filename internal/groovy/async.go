@@ -0,0 +1,327 @@
+package groovy
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobUnknown   = "unknown"
+)
+
+// jobPreamble locates the directory of a job in the server's temp directory.
+const jobPreamble = `def jobDir = new File(System.getProperty('java.io.tmpdir'), 'hactools-jobs/%s')
+def statusFile = new File(jobDir, 'status.json')
+def logFile = new File(jobDir, 'output.log')
+`
+
+// submitScript starts the script in a background thread of the server and
+// returns immediately. The thread runs with the tenant and user of the
+// request, writes the script output to output.log and its state, result and
+// stack trace to status.json. Without commit the script runs in a
+// transaction that is rolled back, like in the console.
+const submitScript = `import de.hybris.platform.core.Registry
+import de.hybris.platform.jalo.JaloSession
+import de.hybris.platform.tx.Transaction
+import groovy.json.JsonOutput
+
+class JobBinding extends Binding {
+    def context
+
+    JobBinding(context) {
+        this.context = context
+    }
+
+    Object getVariable(String name) {
+        if (!getVariables().containsKey(name) && context.containsBean(name)) {
+            return context.getBean(name)
+        }
+        return super.getVariable(name)
+    }
+
+    boolean hasVariable(String name) {
+        return super.hasVariable(name) || context.containsBean(name)
+    }
+}
+
+` + jobPreamble + `
+jobDir.mkdirs()
+
+def node = InetAddress.localHost.hostName
+def writeStatus = { Map status ->
+    def tmp = new File(jobDir, 'status.json.tmp')
+    tmp.setText(JsonOutput.toJson(status), 'UTF-8')
+    tmp.renameTo(statusFile)
+}
+
+def started = System.currentTimeMillis()
+writeStatus([id: '%s', state: 'running', node: node, startedAt: started])
+
+def context = spring
+def tenant = Registry.currentTenant
+def user = spring.getBean('userService').currentUser
+def scriptText = new String('%s'.decodeBase64(), 'UTF-8')
+def commit = %t
+def loader = this.class.classLoader
+
+Thread.start('hactools-job-%s') {
+    def writer = new PrintWriter(new OutputStreamWriter(new FileOutputStream(logFile), 'UTF-8'), true)
+    def status = [id: '%s', node: node, startedAt: started]
+    Registry.setCurrentTenant(tenant)
+    try {
+        JaloSession.currentSession
+        context.getBean('userService').setCurrentUser(user)
+
+        def jobBinding = new JobBinding(context)
+        jobBinding.setVariable('out', writer)
+
+        def tx = commit ? null : Transaction.current()
+        tx?.begin()
+        try {
+            def result = new GroovyShell(loader, jobBinding).evaluate(scriptText, 'Script0.groovy')
+            status += [state: 'succeeded', result: result == null ? '' : result.toString()]
+        } finally {
+            tx?.rollback()
+        }
+    } catch (Throwable e) {
+        def trace = new StringWriter()
+        e.printStackTrace(new PrintWriter(trace))
+        status += [state: 'failed', stacktrace: trace.toString()]
+    } finally {
+        writer.close()
+        status.finishedAt = System.currentTimeMillis()
+        writeStatus(status)
+        JaloSession.deactivate()
+        Registry.unsetCurrentTenant()
+    }
+}
+
+return '%s'
+`
+
+// statusScript returns status.json with the current size of the log.
+const statusScript = `import groovy.json.JsonOutput
+import groovy.json.JsonSlurper
+
+` + jobPreamble + `
+def status = statusFile.exists() ? new JsonSlurper().parse(statusFile, 'UTF-8') : [id: '%s', state: 'unknown']
+status.logSize = logFile.exists() ? logFile.length() : 0
+return JsonOutput.toJson(status)
+`
+
+// logsScript returns the log from the given byte offset, base64 encoded.
+const logsScript = jobPreamble + `
+if (!logFile.exists()) {
+    return ''
+}
+
+def file = new RandomAccessFile(logFile, 'r')
+try {
+    def offset = Math.min(%dL, file.length())
+    def bytes = new byte[(int) (file.length() - offset)]
+    file.seek(offset)
+    file.readFully(bytes)
+    return bytes.encodeBase64().toString()
+} finally {
+    file.close()
+}
+`
+
+var jobIDPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// Submit starts script in the background on the server and records the job
+// locally so that its stack traces can be mapped later.
+func (e *GroovyExecutor) Submit(script string, opts models.GroovyExecuteOptions) (*models.GroovyJob, error) {
+	if opts.ScriptType != "groovy" {
+		return nil, fmt.Errorf("async execution is only supported for groovy scripts")
+	}
+	if opts.DryRun {
+		return nil, fmt.Errorf("dry runs cannot be async")
+	}
+
+	script, sourceMap, err := prepare(script, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	submit := fmt.Sprintf(submitScript, id, id, base64.StdEncoding.EncodeToString([]byte(script)), opts.Commit, id, id, id)
+	if _, err := e.run(submit); err != nil {
+		return nil, fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	job := &models.GroovyJob{
+		ID:          id,
+		Address:     e.Client.BaseURL,
+		Profile:     e.Profile,
+		Route:       e.Client.Route(),
+		Path:        opts.Path,
+		Commit:      opts.Commit,
+		SubmittedAt: time.Now(),
+		SourceMap:   sourceMap,
+	}
+	return job, SaveJob(job)
+}
+
+// JobStatus reads the state of a job from the server.
+func (e *GroovyExecutor) JobStatus(id string) (*models.GroovyJobStatus, error) {
+	if !jobIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid job id: %s", id)
+	}
+
+	result, err := e.run(fmt.Sprintf(statusScript, id, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job status: %w", err)
+	}
+
+	var status models.GroovyJobStatus
+	if err := json.Unmarshal([]byte(result), &status); err != nil {
+		return nil, fmt.Errorf("failed to decode job status: %w, result: %s", err, result)
+	}
+	return &status, nil
+}
+
+// JobLogs returns the output a job wrote after offset.
+func (e *GroovyExecutor) JobLogs(id string, offset int64) (string, error) {
+	if !jobIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid job id: %s", id)
+	}
+
+	result, err := e.run(fmt.Sprintf(logsScript, id, offset))
+	if err != nil {
+		return "", fmt.Errorf("failed to read job logs: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode job logs: %w", err)
+	}
+	return string(data), nil
+}
+
+// Wait polls a job every interval until it finishes and returns its output,
+// result and stack trace as a response. A zero timeout waits forever.
+func (e *GroovyExecutor) Wait(id string, interval, timeout time.Duration) (*models.GroovyResponse, error) {
+	start := time.Now()
+	for {
+		status, err := e.JobStatus(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status.State {
+		case JobSucceeded, JobFailed:
+			return e.jobResponse(status)
+		case JobUnknown:
+			return nil, fmt.Errorf("job %s not found on %s", id, e.Client.BaseURL)
+		}
+
+		if timeout > 0 && time.Since(start) > timeout {
+			return nil, fmt.Errorf("timed out after %s waiting for job %s", timeout, id)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (e *GroovyExecutor) jobResponse(status *models.GroovyJobStatus) (*models.GroovyResponse, error) {
+	output, err := e.JobLogs(status.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceMap []models.SourceLine
+	if job, err := LoadJob(status.ID); err == nil {
+		sourceMap = job.SourceMap
+	}
+
+	return &models.GroovyResponse{
+		ExecutionResult: status.Result,
+		ScriptResult:    strings.TrimSuffix(output, "\n"),
+		StacktraceText:  RemapStacktrace(status.Stacktrace, sourceMap),
+		Success:         status.State == JobSucceeded,
+		SourceMap:       sourceMap,
+		Duration:        time.Duration(status.FinishedAt-status.StartedAt) * time.Millisecond,
+	}, nil
+}
+
+// run executes a generated script without commit and returns its result.
+func (e *GroovyExecutor) run(script string) (string, error) {
+	resp, err := e.Client.ExecuteGroovy(map[string]any{
+		"script":     script,
+		"_csrf":      e.Client.Csrf,
+		"scriptType": "groovy",
+		"commit":     false,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.StacktraceText != "" {
+		return "", fmt.Errorf("script failed: %s", firstLine(resp.StacktraceText))
+	}
+	return resp.ExecutionResult, nil
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func jobsDir() string {
+	return filepath.Join(options.ConfigDir(), "jobs")
+}
+
+// SaveJob records job in the local jobs directory.
+func SaveJob(job *models.GroovyJob) error {
+	if err := os.MkdirAll(jobsDir(), 0o700); err != nil {
+		return fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(jobsDir(), job.ID+".json"), data, 0o600); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// LoadJob reads the local record of a job.
+func LoadJob(id string) (*models.GroovyJob, error) {
+	if !jobIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid job id: %s", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(jobsDir(), id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+
+	var job models.GroovyJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	return &job, nil
+}
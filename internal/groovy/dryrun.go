@@ -0,0 +1,168 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+// innerScriptName names the wrapped script so that its compiler errors and
+// stack frames are remapped like those of a script run directly. Console
+// scripts are numbered from 1, so the wrapper never shares this name.
+const innerScriptName = "Script0.groovy"
+
+// evaluateFragment evaluates a base64 encoded script with the binding of the
+// console script into result. Frames of the wrapper are stripped from
+// exceptions so traces only show the evaluated script.
+const evaluateFragment = `def wrapper = this.class.name
+def result
+try {
+    result = new GroovyShell(this.class.classLoader, binding).evaluate(new String('%s'.decodeBase64(), 'UTF-8'), '` + innerScriptName + `')
+} catch (Throwable e) {
+    for (def cause = e; cause != null; cause = cause.cause) {
+        cause.stackTrace = cause.stackTrace.findAll { it.className != wrapper && !it.className.startsWith(wrapper + '$') } as StackTraceElement[]
+    }
+    throw e
+}
+`
+
+// dryRunScript binds a proxy of the model service that records the items
+// saved and removed through it, evaluates the script with the same binding
+// and returns its result together with the recorded changes. Only calls
+// through the modelService variable are recorded: the bean of the
+// application context stays the real service.
+const dryRunScript = `import de.hybris.platform.core.PK
+import de.hybris.platform.core.model.ItemModel
+import de.hybris.platform.servicelayer.model.ItemModelContextImpl
+import de.hybris.platform.servicelayer.model.ModelService
+import groovy.json.JsonOutput
+import java.lang.reflect.InvocationHandler
+import java.lang.reflect.InvocationTargetException
+import java.lang.reflect.Proxy
+
+def target = spring.getBean('modelService')
+def changes = []
+
+def describe = { String action, Object item ->
+    def model = item instanceof PK ? target.get(item) : item
+    if (!(model instanceof ItemModel)) {
+        return null
+    }
+
+    def attributes = []
+    def context = model.itemModelContext
+    if (action != 'removed' && context instanceof ItemModelContextImpl) {
+        attributes.addAll(context.dirtyAttributes)
+        context.dirtyLocalizedAttributes.each { locale, names ->
+            names.each { attributes << it + '[' + locale + ']' }
+        }
+    }
+    if (action == 'modified' && attributes.isEmpty()) {
+        return null
+    }
+
+    return [change: [action: action, type: model.itemtype, pk: model.pk?.toString(), attributes: attributes.unique().sort()], model: model]
+}
+
+def itemsOf = { List args ->
+    def items = []
+    args.each { arg ->
+        if (arg instanceof Collection || arg instanceof Object[]) {
+            items.addAll(arg as List)
+        } else {
+            items << arg
+        }
+    }
+    return items
+}
+
+def handler = { proxy, method, args ->
+    def pending = []
+    switch (method.name) {
+        case ['save', 'saveAll']:
+            def items = itemsOf(args?.toList() ?: [])
+            if (method.name == 'saveAll' && items.isEmpty()) {
+                changes << [action: 'saved', type: '*', pk: '', attributes: ['saveAll() without arguments']]
+            }
+            items.each { pending << describe(target.isNew(it) ? 'created' : 'modified', it) }
+            break
+        case ['remove', 'removeAll']:
+            itemsOf(args?.toList() ?: []).each { pending << describe('removed', it) }
+            break
+    }
+
+    def result
+    try {
+        result = method.invoke(target, args ?: new Object[0])
+    } catch (InvocationTargetException e) {
+        throw e.targetException
+    }
+
+    pending.findAll().each { entry ->
+        if (!entry.change.pk) {
+            entry.change.pk = entry.model.pk?.toString()
+        }
+        changes << entry.change
+    }
+    return result
+}
+
+binding.setVariable('modelService', Proxy.newProxyInstance(ModelService.classLoader, [ModelService] as Class[], handler as InvocationHandler))
+
+` + evaluateFragment + `
+return JsonOutput.toJson([result: result == null ? '' : result.toString(), changes: changes])
+`
+
+// trackedCalls describes which changes a dry run sees. Anything else, like
+// a service saving items itself, runs unrecorded and is rolled back too.
+const trackedCalls = "direct modelService calls only; services, spring.getBean('modelService'), jalo and impex are not tracked"
+
+type dryRunResult struct {
+	Result  string                `json:"result"`
+	Changes []models.GroovyChange `json:"changes"`
+}
+
+func wrapDryRun(script string) string {
+	return fmt.Sprintf(dryRunScript, base64.StdEncoding.EncodeToString([]byte(script)))
+}
+
+// unwrapDryRun restores the script's own result and extracts the changes
+// recorded by the dry run wrapper.
+func unwrapDryRun(resp *models.GroovyResponse) error {
+	if resp.StacktraceText != "" {
+		return nil
+	}
+
+	var result dryRunResult
+	if err := json.Unmarshal([]byte(resp.ExecutionResult), &result); err != nil {
+		return fmt.Errorf("failed to decode dry run result: %w", err)
+	}
+
+	resp.ExecutionResult = result.Result
+	resp.Changes = result.Changes
+	if resp.Changes == nil {
+		resp.Changes = []models.GroovyChange{}
+	}
+	return nil
+}
+
+func displayChanges(changes []models.GroovyChange) {
+	fmt.Println("\n=== CHANGES (rolled back) ===")
+	fmt.Printf("Tracked: %s\n", trackedCalls)
+	if len(changes) == 0 {
+		fmt.Println("No changes through modelService")
+		return
+	}
+
+	table := output.NewTable([]string{"Action", "Type", "PK", "Attributes"})
+
+	for _, change := range changes {
+		table.Append([]string{change.Action, change.Type, change.PK, strings.Join(change.Attributes, ", ")})
+	}
+
+	table.Render()
+}
@@ -0,0 +1,178 @@
+package groovy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+const inlineScriptName = "<script>"
+
+var (
+	includePattern = regexp.MustCompile(`^\s*//#include\s+["<]?([^">\s]+)[">]?\s*$`)
+
+	// Console scripts are compiled as Script<N>.groovy; compiler errors
+	// name them "Script1.groovy: 3:" and stack frames "Script1.groovy:3".
+	scriptLinePattern   = regexp.MustCompile(`\bScript\d+(?:\.groovy)?(:\s*)(\d+)`)
+	compilerLinePattern = regexp.MustCompile(`@ line (\d+), column (\d+)`)
+)
+
+type includer struct {
+	includePath []string
+	stack       []string
+	included    map[string]bool
+	lines       []string
+	sourceMap   []models.SourceLine
+}
+
+// Include inlines the //#include directives of script and returns the
+// expanded script with the original location of each of its lines. Includes
+// are resolved relative to the including file, then against includePath.
+// Every file is inlined once; later includes of it are dropped, and include
+// cycles are errors.
+func Include(script, path string, includePath []string) (string, []models.SourceLine, error) {
+	inc := &includer{
+		includePath: includePath,
+		included:    make(map[string]bool),
+	}
+
+	name := inlineScriptName
+	if path != "" {
+		name = path
+		if abs, err := filepath.Abs(path); err == nil {
+			inc.stack = append(inc.stack, abs)
+			inc.included[abs] = true
+		}
+	}
+
+	if err := inc.expand(script, name, filepath.Dir(path)); err != nil {
+		return "", nil, err
+	}
+
+	return strings.Join(inc.lines, "\n"), inc.sourceMap, nil
+}
+
+func (inc *includer) expand(content, name, dir string) error {
+	for i, line := range strings.Split(content, "\n") {
+		location := models.SourceLine{File: name, Line: i + 1, Text: line}
+
+		match := includePattern.FindStringSubmatch(line)
+		if match == nil {
+			inc.lines = append(inc.lines, line)
+			inc.sourceMap = append(inc.sourceMap, location)
+			continue
+		}
+
+		path, err := inc.resolve(match[1], dir)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, location.Line, err)
+		}
+
+		for j, parent := range inc.stack {
+			if parent == path {
+				cycle := append(displayPaths(inc.stack[j:]), displayPath(path))
+				return fmt.Errorf("%s:%d: include cycle: %s", name, location.Line, strings.Join(cycle, " -> "))
+			}
+		}
+
+		if inc.included[path] {
+			inc.lines = append(inc.lines, "")
+			inc.sourceMap = append(inc.sourceMap, location)
+			continue
+		}
+		inc.included[path] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s:%d: failed to read include: %w", name, location.Line, err)
+		}
+
+		inc.stack = append(inc.stack, path)
+		err = inc.expand(strings.TrimSuffix(string(data), "\n"), displayPath(path), filepath.Dir(path))
+		inc.stack = inc.stack[:len(inc.stack)-1]
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (inc *includer) resolve(target, dir string) (string, error) {
+	candidates := []string{target}
+	if !filepath.IsAbs(target) {
+		candidates = []string{filepath.Join(dir, target)}
+		for _, includeDir := range inc.includePath {
+			candidates = append(candidates, filepath.Join(includeDir, target))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return filepath.Abs(candidate)
+		}
+	}
+
+	return "", fmt.Errorf("include %q not found (searched %s)", target, strings.Join(candidates, ", "))
+}
+
+// displayPath shortens path to be relative to the working directory when it
+// is below it.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func displayPaths(paths []string) []string {
+	display := make([]string, len(paths))
+	for i, path := range paths {
+		display[i] = displayPath(path)
+	}
+	return display
+}
+
+// Locate returns the original location of a line of the script that was
+// sent, or false when the line is outside the source map.
+func Locate(sourceMap []models.SourceLine, line int) (models.SourceLine, bool) {
+	if line < 1 || line > len(sourceMap) {
+		return models.SourceLine{}, false
+	}
+	return sourceMap[line-1], true
+}
+
+// RemapStacktrace rewrites the script line numbers of a console stack trace
+// or compiler error to the original file and line.
+func RemapStacktrace(text string, sourceMap []models.SourceLine) string {
+	if text == "" || len(sourceMap) == 0 {
+		return text
+	}
+
+	text = scriptLinePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := scriptLinePattern.FindStringSubmatch(match)
+		line, _ := strconv.Atoi(parts[2])
+		if location, ok := Locate(sourceMap, line); ok {
+			return location.File + parts[1] + strconv.Itoa(location.Line)
+		}
+		return match
+	})
+
+	return compilerLinePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := compilerLinePattern.FindStringSubmatch(match)
+		line, _ := strconv.Atoi(parts[1])
+		if location, ok := Locate(sourceMap, line); ok {
+			return fmt.Sprintf("@ %s line %d, column %s", location.File, location.Line, parts[2])
+		}
+		return match
+	})
+}
@@ -0,0 +1,152 @@
+package groovy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"lib/util.groovy":   "//#include \"common.groovy\"\ndef util() { 1 }\n",
+		"lib/common.groovy": "def common() { 2 }\n",
+		"cycle/a.groovy":    "//#include b.groovy\n",
+		"cycle/b.groovy":    "//#include a.groovy\n",
+	})
+	writeFiles(t, shared, map[string]string{"shared.groovy": "def shared() { 3 }\n"})
+
+	util := filepath.Join(dir, "lib", "util.groovy")
+	common := filepath.Join(dir, "lib", "common.groovy")
+	sharedPath := filepath.Join(shared, "shared.groovy")
+	main := filepath.Join(dir, "main.groovy")
+
+	tests := []struct {
+		name    string
+		script  string
+		path    string
+		want    string
+		wantMap []models.SourceLine
+		wantErr string
+	}{
+		{
+			name:   "no includes",
+			script: "println 1\nprintln 2",
+			want:   "println 1\nprintln 2",
+			wantMap: []models.SourceLine{
+				{File: inlineScriptName, Line: 1, Text: "println 1"},
+				{File: inlineScriptName, Line: 2, Text: "println 2"},
+			},
+		},
+		{
+			name:   "nested include relative to the including file",
+			script: "//#include lib/util.groovy\nprintln util()",
+			path:   main,
+			want:   "def common() { 2 }\ndef util() { 1 }\nprintln util()",
+			wantMap: []models.SourceLine{
+				{File: common, Line: 1, Text: "def common() { 2 }"},
+				{File: util, Line: 2, Text: "def util() { 1 }"},
+				{File: main, Line: 2, Text: "println util()"},
+			},
+		},
+		{
+			name:   "repeated include is inlined once",
+			script: "//#include lib/common.groovy\n//#include <lib/common.groovy>\nprintln common()",
+			path:   main,
+			want:   "def common() { 2 }\n\nprintln common()",
+			wantMap: []models.SourceLine{
+				{File: common, Line: 1, Text: "def common() { 2 }"},
+				{File: main, Line: 2, Text: "//#include <lib/common.groovy>"},
+				{File: main, Line: 3, Text: "println common()"},
+			},
+		},
+		{
+			name:   "include path",
+			script: "//#include shared.groovy",
+			path:   main,
+			want:   "def shared() { 3 }",
+			wantMap: []models.SourceLine{
+				{File: sharedPath, Line: 1, Text: "def shared() { 3 }"},
+			},
+		},
+		{
+			name:    "cycle",
+			script:  "//#include cycle/a.groovy",
+			path:    main,
+			wantErr: "include cycle",
+		},
+		{
+			name:    "missing include",
+			script:  "println 1\n//#include missing.groovy",
+			path:    main,
+			wantErr: "main.groovy:2: include \"missing.groovy\" not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, sourceMap, err := Include(tt.script, tt.path, []string{shared})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Include() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Include() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Include() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(sourceMap, tt.wantMap) {
+				t.Errorf("source map = %+v, want %+v", sourceMap, tt.wantMap)
+			}
+		})
+	}
+}
+
+func TestRemapStacktrace(t *testing.T) {
+	sourceMap := []models.SourceLine{
+		{File: "<preamble>", Line: 1},
+		{File: "lib/util.groovy", Line: 7},
+		{File: "main.groovy", Line: 3},
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"stack frame", "\tat Script1.run(Script1.groovy:3)", "\tat Script1.run(main.groovy:3)"},
+		{"frame of an included method", "\tat Script12.util(Script12.groovy:2)", "\tat Script12.util(lib/util.groovy:7)"},
+		{"compiler error", "Script1.groovy: 2: unable to resolve class Foo\n @ line 2, column 5.", "lib/util.groovy: 7: unable to resolve class Foo\n @ lib/util.groovy line 7, column 5."},
+		{"line outside the map", "\tat Script1.run(Script1.groovy:9)", "\tat Script1.run(Script1.groovy:9)"},
+		{"other frames", "\tat java.lang.Thread.run(Thread.java:833)", "\tat java.lang.Thread.run(Thread.java:833)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemapStacktrace(tt.text, sourceMap); got != tt.want {
+				t.Errorf("RemapStacktrace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+// replSessionTTL is how long the variables of an idle REPL session are kept
+// on the server. Sessions are also dropped when the REPL is left.
+const replSessionTTL = time.Hour
+
+// Limits of the REPL sessions of a node, so that abandoned sessions cannot
+// hold on to much memory: the number of sessions, the variables of a
+// session and the elements of a collection or array kept as a variable.
+const (
+	replMaxSessions = 10
+	replMaxVars     = 50
+	replMaxElements = 10000
+)
+
+// replSessionsFragment looks up the REPL sessions of the server, a map kept
+// as a singleton of the application context so that it outlives the console
+// requests, and drops the sessions idle for longer than the TTL.
+const replSessionsFragment = `import java.util.concurrent.ConcurrentHashMap
+
+def factory = spring.autowireCapableBeanFactory
+def sessions
+synchronized (factory) {
+    if (!factory.containsSingleton('hactoolsReplSessions')) {
+        factory.registerSingleton('hactoolsReplSessions', new ConcurrentHashMap())
+    }
+    sessions = factory.getSingleton('hactoolsReplSessions')
+}
+def now = System.currentTimeMillis()
+sessions.values().removeIf { now - it.touched > %d }
+`
+
+// replScript restores the variables of a REPL session, evaluates the input
+// with the console binding and stores every variable it did not bring itself
+// back into the session, so that variables survive between inputs. A new
+// session is refused when the node has too many, and variables beyond the
+// limits are not kept.
+const replScript = replSessionsFragment + `
+def replId = '%s'
+def maxSessions = %d
+def maxVars = %d
+def maxElements = %d
+def session = sessions.get(replId)
+if (session == null) {
+    if (sessions.size() >= maxSessions) {
+        throw new IllegalStateException("too many REPL sessions on this node (limit ${maxSessions}), leave another REPL or wait for idle ones to expire")
+    }
+    session = [vars: Collections.synchronizedMap([:])]
+    sessions.put(replId, session)
+}
+session.touched = now
+def state = session.vars
+def reserved = new HashSet(binding.variables.keySet())
+state.each { name, value -> binding.setVariable(name, value) }
+
+` + evaluateFragment + `
+def dropped = []
+binding.variables.each { name, value ->
+    if (reserved.contains(name)) {
+        return
+    }
+    def size = value instanceof Collection || value instanceof Map ? value.size() : value?.getClass()?.isArray() ? value.length : 0
+    if (size > maxElements || (!state.containsKey(name) && state.size() >= maxVars)) {
+        state.remove(name)
+        dropped << name
+        return
+    }
+    state.put(name, value)
+}
+if (dropped) {
+    println "not kept in the session: ${dropped.join(', ')} (limits: ${maxVars} variables, ${maxElements} elements)"
+}
+return result
+`
+
+const replVarsScript = `import groovy.json.JsonOutput
+` + replSessionsFragment + `
+def state = sessions.get('%s')?.vars ?: [:]
+return JsonOutput.toJson(state.collectEntries { name, value -> [name, value == null ? 'null' : value.getClass().name] })
+`
+
+const replResetScript = replSessionsFragment + `
+sessions.remove('%s')
+return ''
+`
+
+var (
+	importPattern = regexp.MustCompile(`^import\s+[\w.*]+(\s+as\s+\w+)?\s*;?\s*$`)
+
+	// Top level declarations become binding assignments so that the
+	// variables outlive the input.
+	declarationPattern = regexp.MustCompile(`^(?:def|var|final|boolean|byte|char|short|int|long|float|double|[A-Z][\w.]*(?:<.*?>)?(?:\[\])*)\s+([A-Za-z_]\w*)\s*(=[^=~].*|=)?$`)
+
+	// definitionPattern captures the name of a class in the first group and
+	// of a method in the second.
+	definitionPattern = regexp.MustCompile(`^(?:(?:(?:public|private|protected|static|final|abstract)\s+)*(?:class|interface|enum|trait)\s+(\w+)|(?:(?:public|private|protected|static|final)\s+)*(?:def|void|[A-Za-z_][\w.]*(?:<.*?>)?(?:\[\])*)\s+(\w+)\s*\([^)]*\)\s*\{)`)
+)
+
+// Session emulates a persistent Groovy shell on top of independent console
+// requests. Variables are kept on the server, while imports, methods and
+// classes are kept locally and sent again with every input.
+type Session struct {
+	Executor    *GroovyExecutor
+	ID          string
+	ScriptType  string
+	Commit      bool
+	IncludePath []string
+
+	imports     []models.SourceLine
+	definitions []definition
+	inputs      int
+}
+
+// definition is a top level method or class of a session. A later
+// definition with the same name replaces it.
+type definition struct {
+	name  string
+	lines []models.SourceLine
+}
+
+func NewSession(executor *GroovyExecutor) (*Session, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		Executor:   executor,
+		ID:         id,
+		ScriptType: "groovy",
+	}, nil
+}
+
+// Eval runs input, or the file at path when path is set. Groovy inputs see
+// the variables, imports and definitions of every earlier successful input.
+func (s *Session) Eval(input, path string) (*models.GroovyResponse, error) {
+	s.inputs++
+
+	if s.ScriptType != "groovy" {
+		return s.Executor.Execute(input, models.GroovyExecuteOptions{
+			ScriptType: s.ScriptType,
+			Commit:     s.Commit,
+		})
+	}
+
+	expanded, sourceMap, err := Include(input, path, s.IncludePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve includes: %w", err)
+	}
+
+	name := fmt.Sprintf("<repl:%d>", s.inputs)
+	lines := strings.Split(expanded, "\n")
+	var imports []models.SourceLine
+	for i, line := range lines {
+		if path == "" && sourceMap[i].File == inlineScriptName {
+			sourceMap[i].File = name
+		}
+
+		switch {
+		case importPattern.MatchString(line):
+			imports = append(imports, sourceMap[i])
+			lines[i] = ""
+		case sourceMap[i].File == name:
+			lines[i] = declarationPattern.ReplaceAllStringFunc(line, rewriteDeclaration)
+		}
+	}
+
+	var body []string
+	var bodyMap []models.SourceLine
+	for _, source := range append(append(append([]models.SourceLine{}, s.imports...), imports...), s.definitionLines()...) {
+		body = append(body, source.Text)
+		bodyMap = append(bodyMap, source)
+	}
+	body = append(body, lines...)
+	bodyMap = append(bodyMap, sourceMap...)
+
+	script := fmt.Sprintf(replScript, replSessionTTL.Milliseconds(), s.ID, replMaxSessions, replMaxVars, replMaxElements,
+		base64.StdEncoding.EncodeToString([]byte(strings.Join(body, "\n"))))
+
+	start := time.Now()
+	resp, err := s.Executor.Client.ExecuteGroovy(map[string]any{
+		"script":     script,
+		"_csrf":      s.Executor.Client.Csrf,
+		"scriptType": "groovy",
+		"commit":     s.Commit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Duration = time.Since(start)
+	resp.SourceMap = bodyMap
+	resp.StacktraceText = RemapStacktrace(resp.StacktraceText, bodyMap)
+
+	if resp.StacktraceText == "" {
+		s.imports = append(s.imports, imports...)
+		for _, block := range definitionBlocks(lines) {
+			def := definition{name: block.name}
+			for _, i := range block.lines {
+				def.lines = append(def.lines, sourceMap[i])
+			}
+			s.define(def)
+		}
+	}
+
+	return resp, nil
+}
+
+// Vars returns the variables of the session with the class of their value.
+func (s *Session) Vars() (map[string]string, error) {
+	result, err := s.Executor.run(fmt.Sprintf(replVarsScript, replSessionTTL.Milliseconds(), s.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables: %w", err)
+	}
+
+	vars := make(map[string]string)
+	if err := json.Unmarshal([]byte(result), &vars); err != nil {
+		return nil, fmt.Errorf("failed to decode variables: %w", err)
+	}
+	return vars, nil
+}
+
+// Definitions returns the imports, methods and classes replayed with every
+// input.
+func (s *Session) Definitions() []models.SourceLine {
+	return append(append([]models.SourceLine{}, s.imports...), s.definitionLines()...)
+}
+
+// define adds def to the session, replacing an earlier definition with the
+// same name.
+func (s *Session) define(def definition) {
+	for i, previous := range s.definitions {
+		if previous.name == def.name {
+			s.definitions[i] = def
+			return
+		}
+	}
+	s.definitions = append(s.definitions, def)
+}
+
+func (s *Session) definitionLines() []models.SourceLine {
+	var lines []models.SourceLine
+	for _, def := range s.definitions {
+		lines = append(lines, def.lines...)
+	}
+	return lines
+}
+
+// Reset forgets the variables and definitions of the session.
+func (s *Session) Reset() error {
+	s.imports = nil
+	s.definitions = nil
+
+	if _, err := s.Executor.run(fmt.Sprintf(replResetScript, replSessionTTL.Milliseconds(), s.ID)); err != nil {
+		return fmt.Errorf("failed to reset session: %w", err)
+	}
+	return nil
+}
+
+func rewriteDeclaration(line string) string {
+	match := declarationPattern.FindStringSubmatch(line)
+	if match[2] == "" {
+		return match[1] + " = null"
+	}
+	return match[1] + " " + match[2]
+}
+
+// definitionBlock is a top level method or class definition of an input,
+// with the indexes of its lines.
+type definitionBlock struct {
+	name  string
+	lines []int
+}
+
+// definitionBlocks returns the top level method and class definitions of
+// lines.
+func definitionBlocks(lines []string) []definitionBlock {
+	var blocks []definitionBlock
+	for i := 0; i < len(lines); i++ {
+		match := definitionPattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+
+		block := definitionBlock{name: match[1] + match[2], lines: []int{i}}
+		text := lines[i]
+		for Incomplete(text) && i+1 < len(lines) {
+			i++
+			text += "\n" + lines[i]
+			block.lines = append(block.lines, i)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Incomplete reports whether input has unclosed brackets, strings or a
+// trailing backslash and needs more lines.
+func Incomplete(input string) bool {
+	if strings.HasSuffix(strings.TrimRight(input, " \t"), "\\") {
+		return true
+	}
+
+	depth := 0
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '/':
+			if i+1 < len(input) && input[i+1] == '/' {
+				for i < len(input) && input[i] != '\n' {
+					i++
+				}
+			} else if i+1 < len(input) && input[i+1] == '*' {
+				end := strings.Index(input[i+2:], "*/")
+				if end < 0 {
+					return true
+				}
+				i += end + 3
+			}
+		case '\'', '"':
+			quote := input[i : i+1]
+			if strings.HasPrefix(input[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			end := closingQuote(input[i+len(quote):], quote)
+			if end < 0 && len(quote) == 3 {
+				return true
+			}
+			if end < 0 {
+				// An unterminated string is a syntax error for the
+				// server to report, not a reason to wait for more lines.
+				for i < len(input) && input[i] != '\n' {
+					i++
+				}
+				continue
+			}
+			i += len(quote) + end + len(quote) - 1
+		}
+	}
+
+	return depth > 0
+}
+
+func closingQuote(text, quote string) int {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if len(quote) == 1 && text[i] == '\n' {
+			return -1
+		}
+		if strings.HasPrefix(text[i:], quote) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package groovy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"statement", "println 1", false},
+		{"open brace", "def f() {", true},
+		{"closed brace", "def f() {\n  1\n}", false},
+		{"open bracket", "[1, 2,", true},
+		{"open parenthesis", "foo(1,", true},
+		{"trailing backslash", `def x = 1 + \`, true},
+		{"brace in string", "println '{'", false},
+		{"brace in double quoted string", `println "}{"`, false},
+		{"escaped quote", `println 'it\'s {'`, false},
+		{"brace in line comment", "println 1 // {", false},
+		{"open block comment", "/* {", true},
+		{"closed block comment", "/* { */ println 1", false},
+		{"open triple quoted string", "def s = '''", true},
+		{"closed triple quoted string", "def s = '''\n{\n'''", false},
+		{"unterminated string", "println 'abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Incomplete(tt.input); got != tt.want {
+				t.Errorf("Incomplete(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteDeclaration(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"def x = 1", "x = 1"},
+		{"def x", "x = null"},
+		{"String name = 'a'", "name = 'a'"},
+		{"int count = 3", "count = 3"},
+		{"List<String> names = []", "names = []"},
+		{"String[] parts = s.split(',')", "parts = s.split(',')"},
+		{"def matched = x ==~ /a/", "matched = x ==~ /a/"},
+		{"x = 1", "x = 1"},
+		{"def f() { 1 }", "def f() { 1 }"},
+		{"println x", "println x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := declarationPattern.ReplaceAllStringFunc(tt.line, rewriteDeclaration); got != tt.want {
+				t.Errorf("rewrite(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefinitionBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []definitionBlock
+	}{
+		{"statement", []string{"println 1"}, nil},
+		{"one line method", []string{"def f(x) { x }"}, []definitionBlock{{"f", []int{0}}}},
+		{"multi line method", []string{"int count(String type) {", "  1", "}", "count('A')"}, []definitionBlock{{"count", []int{0, 1, 2}}}},
+		{"class and method", []string{"static class Foo {", "}", "void bar() {}"}, []definitionBlock{{"Foo", []int{0, 1}}, {"bar", []int{2}}}},
+		{"call is not a definition", []string{"foo(1) {", "}"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := definitionBlocks(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("definitionBlocks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefineReplaces(t *testing.T) {
+	line := func(text string) []models.SourceLine { return []models.SourceLine{{Text: text}} }
+
+	s := &Session{}
+	s.define(definition{name: "f", lines: line("def f() { 1 }")})
+	s.define(definition{name: "A", lines: line("class A {}")})
+	s.define(definition{name: "f", lines: line("def f() { 2 }")})
+
+	var got []string
+	for _, source := range s.Definitions() {
+		got = append(got, source.Text)
+	}
+	want := []string{"def f() { 2 }", "class A {}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Definitions() = %v, want %v", got, want)
+	}
+}
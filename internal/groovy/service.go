@@ -1,14 +1,34 @@
 package groovy
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/output"
 	"github.com/Salvadego/HacTools/models"
 )
 
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputRaw  = "raw"
+)
+
 type GroovyExecutor struct {
 	Client *client.HACClient
+	// FullStacktrace disables collapsing framework frames in DisplayResults.
+	FullStacktrace bool
+	// Output selects how DisplayResults prints: text, json or raw.
+	Output string
+	// ParseJSON decodes the execution result as JSON for display.
+	ParseJSON bool
+	// Profile names the target in json output.
+	Profile string
 }
 
 func NewGroovyExecutor(client *client.HACClient) *GroovyExecutor {
@@ -17,7 +37,28 @@ func NewGroovyExecutor(client *client.HACClient) *GroovyExecutor {
 	}
 }
 
+// Execute runs script in the scripting console. Groovy scripts have their
+// //#include directives inlined and, when opts.Vars is set, the variables
+// are validated against the script's @param tags and bound through a
+// generated preamble. Dry runs wrap the script to record the items it
+// changes. The response carries the source map of the script that was sent
+// and its stack trace points at the original files.
 func (e *GroovyExecutor) Execute(script string, opts models.GroovyExecuteOptions) (*models.GroovyResponse, error) {
+	script, sourceMap, err := prepare(script, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		if opts.ScriptType != "groovy" {
+			return nil, fmt.Errorf("dry runs are only supported for groovy scripts")
+		}
+		if opts.Commit {
+			return nil, fmt.Errorf("dry runs cannot be committed")
+		}
+		script = wrapDryRun(script)
+	}
+
 	data := map[string]any{
 		"script":     script,
 		"_csrf":      e.Client.Csrf,
@@ -25,19 +66,132 @@ func (e *GroovyExecutor) Execute(script string, opts models.GroovyExecuteOptions
 		"commit":     opts.Commit,
 	}
 
+	start := time.Now()
 	resp, err := e.Client.ExecuteGroovy(data)
 	if err != nil {
 		return nil, err
 	}
 
+	resp.Duration = time.Since(start)
+
+	if opts.DryRun {
+		if err := unwrapDryRun(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.SourceMap = sourceMap
+	resp.StacktraceText = RemapStacktrace(resp.StacktraceText, sourceMap)
+
 	return resp, nil
 }
 
+// prepare inlines the includes of a groovy script and prepends the preamble
+// binding opts.Vars, returning the script to send and its source map. The
+// @param tags of the script are only enforced when variables were given, so
+// scripts that merely mention @param in a comment still run.
+func prepare(script string, opts models.GroovyExecuteOptions) (string, []models.SourceLine, error) {
+	if opts.ScriptType != "groovy" {
+		if opts.Vars != nil {
+			return "", nil, fmt.Errorf("variables are only supported for groovy scripts")
+		}
+		return script, nil, nil
+	}
+
+	var preamble string
+	if opts.Vars != nil {
+		var err error
+		preamble, err = Preamble(script, opts.Vars)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	expanded, expandedMap, err := Include(script, opts.Path, opts.IncludePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve includes: %w", err)
+	}
+
+	var sourceMap []models.SourceLine
+	if preamble != "" {
+		sourceMap = append(sourceMap, models.SourceLine{
+			File: "<preamble>",
+			Line: 1,
+			Text: strings.TrimSuffix(preamble, "\n"),
+		})
+	}
+	sourceMap = append(sourceMap, expandedMap...)
+
+	return preamble + expanded, sourceMap, nil
+}
+
 func (e *GroovyExecutor) DisplayResults(result *models.GroovyResponse) error {
 	if result == nil {
 		return fmt.Errorf("no results to display")
 	}
 
+	switch e.Output {
+	case OutputJSON:
+		return e.displayJSON(result)
+	case OutputRaw:
+		return e.displayRaw(result)
+	case "", OutputText:
+		return e.displayText(result)
+	default:
+		return fmt.Errorf("invalid output format: %s (must be text, json or raw)", e.Output)
+	}
+}
+
+// DisplayNodeResults prints the results of a script that ran on several
+// nodes: one section per node as text, a JSON array, or the raw output
+// prefixed with the node. It fails when the script failed on any node.
+func (e *GroovyExecutor) DisplayNodeResults(results []*models.GroovyResponse) error {
+	var failed []string
+	for _, result := range results {
+		if result.StacktraceText != "" {
+			failed = append(failed, result.Node)
+		}
+	}
+
+	switch e.Output {
+	case OutputJSON:
+		outputs := make([]models.GroovyOutput, len(results))
+		for i, result := range results {
+			outputs[i] = e.output(result)
+		}
+
+		if err := output.JSON(outputs); err != nil {
+			return err
+		}
+	case OutputRaw:
+		for _, result := range results {
+			prefix := "[" + result.Node + "] "
+			if result.ScriptResult != "" {
+				fmt.Println(prefix + strings.ReplaceAll(strings.TrimSuffix(result.ScriptResult, "\n"), "\n", "\n"+prefix))
+			}
+			if result.StacktraceText != "" {
+				fmt.Fprintln(os.Stderr, prefix+firstLine(result.StacktraceText))
+			}
+		}
+	case "", OutputText:
+		for i, result := range results {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("##### NODE %s #####\n", result.Node)
+			_ = e.displayText(result)
+		}
+	default:
+		return fmt.Errorf("invalid output format: %s (must be text, json or raw)", e.Output)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("script execution failed on node %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (e *GroovyExecutor) displayText(result *models.GroovyResponse) error {
 	fmt.Println("=== OUTPUT ===")
 	if result.ScriptResult != "" {
 		fmt.Println(result.ScriptResult)
@@ -47,14 +201,123 @@ func (e *GroovyExecutor) DisplayResults(result *models.GroovyResponse) error {
 
 	if result.ExecutionResult != "" {
 		fmt.Println("\n=== RESULT ===")
-		fmt.Println(result.ExecutionResult)
+		if value, ok := e.parseResult(result); ok {
+			encoded, _ := json.MarshalIndent(value, "", "  ")
+			fmt.Println(string(encoded))
+		} else {
+			fmt.Println(result.ExecutionResult)
+		}
+	}
+
+	if result.Changes != nil {
+		displayChanges(result.Changes)
 	}
 
 	if result.StacktraceText != "" {
+		if diagnostic, ok := Diagnose(result.StacktraceText, result.SourceMap); ok {
+			fmt.Println("\n=== ERROR ===")
+			fmt.Println(diagnostic)
+			fmt.Print(diagnostic.Snippet(result.SourceMap))
+		}
+
 		fmt.Println("\n=== STACKTRACE ===")
-		fmt.Println(result.StacktraceText)
+		fmt.Println(e.stacktrace(result))
+		return fmt.Errorf("script execution failed with error")
+	}
+
+	return nil
+}
+
+// displayJSON prints a single JSON document so that xg can be piped into jq.
+func (e *GroovyExecutor) displayJSON(result *models.GroovyResponse) error {
+	doc := e.output(result)
+
+	if err := output.JSON(doc); err != nil {
+		return err
+	}
+
+	if !doc.Success {
+		return fmt.Errorf("script execution failed with error")
+	}
+	return nil
+}
+
+// output builds the machine readable form of result.
+func (e *GroovyExecutor) output(result *models.GroovyResponse) models.GroovyOutput {
+	output := models.GroovyOutput{
+		Success:    result.StacktraceText == "",
+		Output:     result.ScriptResult,
+		Result:     result.ExecutionResult,
+		Exception:  result.ExceptionText,
+		DurationMs: result.Duration.Milliseconds(),
+		Profile:    e.Profile,
+		Node:       result.Node,
+	}
+	if result.Changes != nil {
+		output.DryRun = &models.GroovyDryRun{Tracked: trackedCalls, Changes: result.Changes}
+	}
+
+	if value, ok := e.parseResult(result); ok {
+		output.Result = value
+	}
+
+	if result.StacktraceText != "" {
+		output.Stacktrace = e.stacktrace(result)
+		if diagnostic, ok := Diagnose(result.StacktraceText, result.SourceMap); ok {
+			if output.Exception == "" {
+				output.Exception = diagnostic.Message
+			}
+			output.Location = &models.GroovyLocation{
+				File:   diagnostic.Location.File,
+				Line:   diagnostic.Location.Line,
+				Column: diagnostic.Column,
+			}
+		} else if output.Exception == "" {
+			output.Exception = firstLine(result.StacktraceText)
+		}
+	}
+	return output
+}
+
+// displayRaw prints only the script output, reporting errors on stderr.
+func (e *GroovyExecutor) displayRaw(result *models.GroovyResponse) error {
+	fmt.Print(result.ScriptResult)
+
+	if result.StacktraceText != "" {
+		if diagnostic, ok := Diagnose(result.StacktraceText, result.SourceMap); ok {
+			fmt.Fprintln(os.Stderr, diagnostic)
+		} else {
+			fmt.Fprintln(os.Stderr, firstLine(result.StacktraceText))
+		}
 		return fmt.Errorf("script execution failed with error")
 	}
 
 	return nil
 }
+
+// parseResult decodes the execution result as JSON when ParseJSON is set.
+func (e *GroovyExecutor) parseResult(result *models.GroovyResponse) (any, bool) {
+	if !e.ParseJSON || result.ExecutionResult == "" {
+		return nil, false
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(result.ExecutionResult), &value); err != nil {
+		logger.Error("Result is not valid JSON: %v", err)
+		return nil, false
+	}
+	return value, true
+}
+
+func (e *GroovyExecutor) stacktrace(result *models.GroovyResponse) string {
+	stacktrace := result.StacktraceText
+	if !e.FullStacktrace {
+		stacktrace = CollapseStacktrace(stacktrace)
+	}
+	return strings.TrimRight(stacktrace, "\n")
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
@@ -0,0 +1,158 @@
+package groovy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+const snippetContext = 2
+
+var (
+	framePattern       = regexp.MustCompile(`^\s*at (\S+)\((.*?)(?::(\d+))?\)\s*$`)
+	scriptClassPattern = regexp.MustCompile(`^Script\d+[$.]`)
+	compilerPattern    = regexp.MustCompile(`^(.+?): ?(\d+): (.+?)(?:\s*@ .*line \d+, column (\d+)\.?)?$`)
+	columnPattern      = regexp.MustCompile(`@ .*line (\d+), column (\d+)`)
+)
+
+// Diagnostic is the location in the original sources an error is reported
+// at, in the style of a compiler message.
+type Diagnostic struct {
+	Location models.SourceLine
+	Column   int
+	Message  string
+}
+
+// Diagnose finds the script location of a compiler error or of the innermost
+// script frame of a stack trace that was remapped with RemapStacktrace.
+func Diagnose(stacktrace string, sourceMap []models.SourceLine) (*Diagnostic, bool) {
+	files := make(map[string]bool)
+	for _, line := range sourceMap {
+		files[line.File] = true
+	}
+
+	lines := strings.Split(strings.TrimSpace(stacktrace), "\n")
+
+	for i, line := range lines {
+		match := compilerPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || !files[match[1]] {
+			continue
+		}
+
+		lineNumber, _ := strconv.Atoi(match[2])
+		diagnostic := &Diagnostic{
+			Location: findSource(sourceMap, match[1], lineNumber),
+			Message:  match[3],
+		}
+		diagnostic.Column, _ = strconv.Atoi(match[4])
+		if diagnostic.Column == 0 && i+1 < len(lines) {
+			if column := columnPattern.FindStringSubmatch(lines[i+1]); column != nil {
+				diagnostic.Column, _ = strconv.Atoi(column[2])
+			}
+		}
+		return diagnostic, true
+	}
+
+	for _, line := range lines {
+		match := framePattern.FindStringSubmatch(line)
+		if match == nil || !scriptClassPattern.MatchString(match[1]) || !files[match[2]] {
+			continue
+		}
+
+		lineNumber, _ := strconv.Atoi(match[3])
+		return &Diagnostic{
+			Location: findSource(sourceMap, match[2], lineNumber),
+			Message:  strings.TrimSpace(lines[0]),
+		}, true
+	}
+
+	return nil, false
+}
+
+func findSource(sourceMap []models.SourceLine, file string, line int) models.SourceLine {
+	for _, source := range sourceMap {
+		if source.File == file && source.Line == line {
+			return source
+		}
+	}
+	return models.SourceLine{File: file, Line: line}
+}
+
+// String formats the diagnostic as file:line[:column]: message.
+func (d *Diagnostic) String() string {
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", d.Location.File, d.Location.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.Location.File, d.Location.Line, d.Message)
+}
+
+// Snippet renders the lines around the diagnostic's location that come from
+// the same file, marking the offending line and column.
+func (d *Diagnostic) Snippet(sourceMap []models.SourceLine) string {
+	index := -1
+	for i, source := range sourceMap {
+		if source.File == d.Location.File && source.Line == d.Location.Line {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i := max(index-snippetContext, 0); i <= min(index+snippetContext, len(sourceMap)-1); i++ {
+		source := sourceMap[i]
+		if source.File != d.Location.File {
+			continue
+		}
+
+		marker := " "
+		if i == index {
+			marker = ">"
+		}
+		prefix := fmt.Sprintf("%s %5d | ", marker, source.Line)
+		fmt.Fprintf(&b, "%s%s\n", prefix, source.Text)
+
+		if i == index && d.Column > 0 && d.Column <= len(source.Text)+1 {
+			indent := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, source.Text[:d.Column-1])
+			fmt.Fprintf(&b, "%s%s^\n", strings.Repeat(" ", len(prefix)), indent)
+		}
+	}
+
+	return b.String()
+}
+
+// CollapseStacktrace replaces runs of frames outside the script with a
+// single line counting them.
+func CollapseStacktrace(stacktrace string) string {
+	var b strings.Builder
+	collapsed := 0
+
+	flush := func() {
+		if collapsed > 0 {
+			fmt.Fprintf(&b, "\t... %d framework frames\n", collapsed)
+			collapsed = 0
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(stacktrace, "\n"), "\n") {
+		if match := framePattern.FindStringSubmatch(line); match != nil && !scriptClassPattern.MatchString(match[1]) {
+			collapsed++
+			continue
+		}
+		flush()
+		b.WriteString(line + "\n")
+	}
+	flush()
+
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package groovy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/library"
+	"gopkg.in/yaml.v3"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadVars merges the variables of a YAML vars file with name=value pairs
+// given on the command line, which take precedence.
+func LoadVars(file string, pairs []string) (map[string]any, error) {
+	vars := make(map[string]any)
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vars file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &vars); err != nil {
+			return nil, fmt.Errorf("failed to parse vars file %s: %w", file, err)
+		}
+	}
+
+	for _, pair := range pairs {
+		name, value, found := strings.Cut(pair, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid variable %q (expected name=value)", pair)
+		}
+		vars[name] = ParseValue(value)
+	}
+
+	return vars, nil
+}
+
+// ParseValue infers the type of a command line value: true/false become
+// booleans, numbers become numbers and everything else stays a string.
+// Numbers with leading zeros are kept as strings so codes like 00042 survive,
+// and quoting a value forces a string.
+func ParseValue(value string) any {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	digits := strings.TrimPrefix(value, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return value
+	}
+
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "xXeEnN") {
+		return json.Number(value)
+	}
+
+	return value
+}
+
+// Preamble validates vars against the @param tags declared in the leading
+// comment block of script and returns Groovy assignments binding them. When
+// the script declares parameters, missing required ones and unknown ones are
+// errors and defaults fill the rest; otherwise every variable is bound. The
+// assignments are on a single line so script line numbers shift by one.
+func Preamble(script string, vars map[string]any) (string, error) {
+	_, params := library.ParseFrontMatter(script)
+
+	bindings := make(map[string]any, len(vars))
+	if len(params) == 0 {
+		for name, value := range vars {
+			bindings[name] = value
+		}
+	} else {
+		var missing []string
+		declared := make(map[string]bool, len(params))
+		for _, param := range params {
+			declared[param.Name] = true
+			if value, ok := vars[param.Name]; ok {
+				bindings[param.Name] = value
+			} else if param.Required {
+				missing = append(missing, param.Name)
+			} else {
+				bindings[param.Name] = ParseValue(param.Default)
+			}
+		}
+
+		if len(missing) > 0 {
+			return "", fmt.Errorf("missing required variables: %s", strings.Join(missing, ", "))
+		}
+
+		for name := range vars {
+			if !declared[name] {
+				return "", fmt.Errorf("unknown variable %q (not declared with @param)", name)
+			}
+		}
+	}
+
+	if len(bindings) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		if !identifierPattern.MatchString(name) {
+			return "", fmt.Errorf("invalid variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	assignments := make([]string, len(names))
+	for i, name := range names {
+		literal, err := groovyLiteral(bindings[name])
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+		assignments[i] = fmt.Sprintf("binding.setVariable('%s', %s)", name, literal)
+	}
+
+	return strings.Join(assignments, "; ") + "\n", nil
+}
+
+func groovyLiteral(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return quoteGroovy(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v) + "L", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "L", nil
+	case uint64:
+		return strconv.FormatUint(v, 10) + "G", nil
+	case *big.Int:
+		return v.String() + "G", nil
+	case float64:
+		return "new BigDecimal('" + strconv.FormatFloat(v, 'f', -1, 64) + "')", nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String() + "L", nil
+		}
+		return "new BigDecimal('" + v.String() + "')", nil
+	case time.Time:
+		return quoteGroovy(v.Format(time.RFC3339)), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			literal, err := groovyLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]any:
+		if len(v) == 0 {
+			return "[:]", nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		entries := make([]string, len(keys))
+		for i, key := range keys {
+			literal, err := groovyLiteral(v[key])
+			if err != nil {
+				return "", err
+			}
+			entries[i] = quoteGroovy(key) + ": " + literal
+		}
+		return "[" + strings.Join(entries, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("unsupported type %T", value)
+	}
+}
+
+func quoteGroovy(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return "'" + replacer.Replace(s) + "'"
+}
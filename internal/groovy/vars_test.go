@@ -0,0 +1,102 @@
+package groovy
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestPreamble(t *testing.T) {
+	const declared = "// @param code product code\n// @param limit=10 max rows\nprintln code\n"
+
+	tests := []struct {
+		name    string
+		script  string
+		vars    map[string]any
+		want    string
+		wantErr string
+	}{
+		{"no params no vars", "println 1\n", nil, "", ""},
+		{"no params binds every var", "println 1\n", map[string]any{"b": "x", "a": int64(1)},
+			"binding.setVariable('a', 1L); binding.setVariable('b', 'x')\n", ""},
+		{"missing required without vars", declared, nil, "", "missing required variables: code"},
+		{"missing required", declared, map[string]any{"limit": int64(5)}, "", "missing required variables: code"},
+		{"default bound", declared, map[string]any{"code": "A1"},
+			"binding.setVariable('code', 'A1'); binding.setVariable('limit', 10L)\n", ""},
+		{"default overridden", declared, map[string]any{"code": "A1", "limit": int64(5)},
+			"binding.setVariable('code', 'A1'); binding.setVariable('limit', 5L)\n", ""},
+		{"unknown variable", declared, map[string]any{"code": "A1", "other": true}, "", `unknown variable "other"`},
+		{"only defaults", "// @param dry=true\nprintln dry\n", nil, "binding.setVariable('dry', true)\n", ""},
+		{"invalid name", "println 1\n", map[string]any{"not-valid": 1}, "", `invalid variable name "not-valid"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Preamble(tt.script, tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Preamble() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Preamble() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Preamble() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  any
+	}{
+		{"true", true},
+		{"false", false},
+		{"null", nil},
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"0", int64(0)},
+		{"00042", "00042"},
+		{"0.5", json.Number("0.5")},
+		{"-1.25", json.Number("-1.25")},
+		{"0x1F", "0x1F"},
+		{"1e3", "1e3"},
+		{"NaN", "NaN"},
+		{`"42"`, "42"},
+		{"'true'", "true"},
+		{"hello", "hello"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := ParseValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseValue(%q) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareVars(t *testing.T) {
+	const script = "// @param code product code\nprintln 1"
+
+	got, _, err := prepare(script, models.GroovyExecuteOptions{ScriptType: "groovy"})
+	if err != nil || got != script {
+		t.Errorf("prepare() without vars = %q, %v, want the script unchanged", got, err)
+	}
+
+	if _, _, err := prepare(script, models.GroovyExecuteOptions{ScriptType: "groovy", Vars: map[string]any{}}); err == nil {
+		t.Error("prepare() with vars did not report the missing required variable")
+	}
+
+	if _, _, err := prepare(script, models.GroovyExecuteOptions{ScriptType: "javascript", Vars: map[string]any{}}); err == nil {
+		t.Error("prepare() bound variables for a javascript script")
+	}
+}
@@ -0,0 +1,170 @@
+package guard
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/config"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/options"
+	"golang.org/x/term"
+)
+
+const (
+	summaryLines     = 5
+	summaryLineWidth = 100
+)
+
+// Stdin is the one buffered reader of os.Stdin. Confirmations read from it,
+// and commands that read stdin themselves, like the REPL, must read through
+// it too so that neither swallows input buffered for the other.
+var Stdin = bufio.NewReader(os.Stdin)
+
+// Environment returns the environment class of the target: the
+// --environment flag or $HYBRIS_ENV, then the profile in the config file.
+func Environment(conf options.Config, fileConfig *config.Config) string {
+	if conf.Environment != "" {
+		return conf.Environment
+	}
+	return fileConfig.EnvironmentFor(conf.Profile)
+}
+
+// protection returns the environment class of the target and whether it is
+// protected. A target whose address belongs to a protected profile or host
+// is protected whatever its environment says, so that an address given with
+// -s and no profile is still guarded.
+func protection(conf options.Config, fileConfig *config.Config) (string, bool) {
+	environment := Environment(conf, fileConfig)
+	if fileConfig.IsProtected(environment) {
+		return environment, true
+	}
+
+	match, ok := fileConfig.ProtectedTarget(conf.Address)
+	if !ok {
+		return environment, false
+	}
+	if environment != "" {
+		logger.Info("Target %s matches protected %s, ignoring environment %s", conf.Address, match, environment)
+	}
+	return match, true
+}
+
+// Confirm guards a mutating operation. On protected environments it shows
+// the target and a summary of payload and asks the user to type the host
+// name, unless --yes-i-mean-prod was given. Without a terminal to ask on,
+// the operation is refused.
+func Confirm(conf options.Config, action, payload string) error {
+	fileConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	environment, protected := protection(conf, fileConfig)
+	if !protected {
+		return nil
+	}
+
+	if conf.YesIMeanProd {
+		logger.Info("Confirmed %s on protected environment %s with --yes-i-mean-prod", action, environment)
+		return nil
+	}
+
+	return ask(conf, environment, true, action, payload)
+}
+
+// ConfirmAlways guards a destructive operation. It asks the user to type the
+// host name on every environment; --yes-i-mean-prod only skips the question
+// on protected environments.
+func ConfirmAlways(conf options.Config, action, payload string) error {
+	fileConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	environment, protected := protection(conf, fileConfig)
+	if protected && conf.YesIMeanProd {
+		logger.Info("Confirmed %s on protected environment %s with --yes-i-mean-prod", action, environment)
+		return nil
+	}
+
+	return ask(conf, environment, protected, action, payload)
+}
+
+// ask shows the target and a summary of payload and asks the user to type
+// the host name.
+func ask(conf options.Config, environment string, protected bool, action, payload string) error {
+	if !isTerminal() {
+		if !protected {
+			return fmt.Errorf("refusing to %s without confirmation", action)
+		}
+		return fmt.Errorf("refusing to %s on protected environment %s without confirmation (pass --yes-i-mean-prod)", action, environment)
+	}
+
+	host := hostOf(conf.Address)
+
+	if protected {
+		fmt.Fprintf(os.Stderr, "You are about to %s on a protected environment.\n\n", action)
+	} else {
+		fmt.Fprintf(os.Stderr, "You are about to %s.\n\n", action)
+	}
+	fmt.Fprintf(os.Stderr, "  Target:      %s\n", conf.Address)
+	if conf.Profile != "" {
+		fmt.Fprintf(os.Stderr, "  Profile:     %s\n", conf.Profile)
+	}
+	if environment != "" {
+		fmt.Fprintf(os.Stderr, "  Environment: %s\n", environment)
+	}
+	fmt.Fprintf(os.Stderr, "  User:        %s\n", conf.User)
+	fmt.Fprintf(os.Stderr, "\n%s\n", Summary(payload))
+	fmt.Fprintf(os.Stderr, "Type the host name (%s) to continue: ", host)
+
+	answer, err := Stdin.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	if strings.TrimSpace(answer) != host {
+		return fmt.Errorf("aborted: confirmation did not match %s", host)
+	}
+
+	return nil
+}
+
+// Summary describes payload by its size and first lines.
+func Summary(payload string) string {
+	var lines []string
+	for _, line := range strings.Split(payload, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Payload (%d lines, %d bytes):\n", len(lines), len(payload))
+	for i, line := range lines {
+		if i == summaryLines {
+			fmt.Fprintf(&b, "    ... %d more lines\n", len(lines)-summaryLines)
+			break
+		}
+		if len(line) > summaryLineWidth {
+			line = line[:summaryLineWidth] + "..."
+		}
+		fmt.Fprintf(&b, "    %s\n", line)
+	}
+
+	return b.String()
+}
+
+func hostOf(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return address
+}
+
+func isTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
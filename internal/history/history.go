@@ -0,0 +1,294 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/filelock"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const redacted = "********"
+
+// tailChunk is the size of the blocks lastID reads from the end of the file.
+const tailChunk = 4096
+
+// secretPatterns match the values of credential keys such as password,
+// j_password, db.password or HYBRIS_PASSWORD, but not identifiers that merely
+// start with them, such as passwordService.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(\b(?:\w+_)?pass(?:word|wd)?\b["']?\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s;,&]+)`),
+	regexp.MustCompile(`(?i)(set(?:Encoded)?Password\s*\(\s*)("[^"]*"|'[^']*')`),
+}
+
+func Path() string {
+	if path, exists := os.LookupEnv("HACTOOLS_HISTORY"); exists && path != "" {
+		return path
+	}
+	return filepath.Join(options.ConfigDir(), "history.jsonl")
+}
+
+func Enabled(conf options.Config) bool {
+	if conf.NoHistory {
+		return false
+	}
+	value := strings.ToLower(os.Getenv("HACTOOLS_NO_HISTORY"))
+	return value == "" || value == "0" || value == "false"
+}
+
+// Redact masks password assignments and the configured password itself.
+func Redact(text string, secrets ...string) string {
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, "${1}"+redacted)
+	}
+	for _, secret := range secrets {
+		if len(secret) >= 3 {
+			text = strings.ReplaceAll(text, secret, redacted)
+		}
+	}
+	return text
+}
+
+// EncodeList stores a list option as a JSON array, so that its values may
+// contain any separator.
+func EncodeList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// DecodeList reads a list option stored by EncodeList. Entries recorded
+// before lists were stored as JSON hold comma separated values.
+func DecodeList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var values []string
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &values) == nil {
+		return values
+	}
+	return strings.Split(value, ",")
+}
+
+func Hash(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// Record completes entry with the connection details, duration and outcome
+// of an execution and appends it to the history file. Redacted entries are
+// stored without a payload hash. Failures are only logged so that history
+// never breaks a command.
+func Record(conf options.Config, entry *models.HistoryEntry, start time.Time, execErr error) {
+	if !Enabled(conf) {
+		return
+	}
+
+	entry.Timestamp = start
+	entry.Profile = conf.Profile
+	entry.Address = conf.Address
+	entry.User = conf.User
+	entry.DurationMs = time.Since(start).Milliseconds()
+	// A redacted entry keeps no hash: the hash of the original payload
+	// would allow checking a guessed secret against it.
+	redacted := Redact(entry.Payload, conf.Password)
+	entry.PayloadHash = ""
+	if redacted == entry.Payload {
+		entry.PayloadHash = Hash(entry.Payload)
+	}
+	entry.Payload = redacted
+	for key, value := range entry.Options {
+		entry.Options[key] = Redact(value, conf.Password)
+	}
+
+	if execErr != nil {
+		entry.ExitStatus = 1
+		entry.Outcome = Redact(execErr.Error(), conf.Password)
+	} else if entry.Outcome == "" {
+		entry.Outcome = "success"
+	}
+
+	if err := appendEntry(entry); err != nil {
+		logger.Error("Failed to record history: %v", err)
+	}
+}
+
+func appendEntry(entry *models.HistoryEntry) error {
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// The lock keeps concurrent executions from taking the same id.
+	release, err := filelock.Acquire(path)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	last, err := lastID(path)
+	if err != nil {
+		return err
+	}
+	entry.ID = last + 1
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	return nil
+}
+
+// lastID returns the id of the last entry, reading the file backwards so
+// that recording does not get slower as the history grows.
+func lastID(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var tail []byte
+	for offset := info.Size(); offset > 0; {
+		size := min(int64(tailChunk), offset)
+		offset -= size
+
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return 0, fmt.Errorf("failed to read history file: %w", err)
+		}
+		tail = append(chunk, tail...)
+
+		trimmed := bytes.TrimRight(tail, " \t\r\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 || offset == 0 {
+			var entry models.HistoryEntry
+			if err := json.Unmarshal(trimmed[i+1:], &entry); err == nil {
+				return entry.ID, nil
+			}
+			break
+		}
+	}
+
+	// A malformed last line falls back to the highest id of the file.
+	entries, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	id := 0
+	for _, entry := range entries {
+		id = max(id, entry.ID)
+	}
+	return id, nil
+}
+
+func Load() ([]models.HistoryEntry, error) {
+	file, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []models.HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var entry models.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logger.Debug("Skipping malformed history line: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return entries, nil
+}
+
+func Search(filter models.HistoryFilter) ([]models.HistoryEntry, error) {
+	entries, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern *regexp.Regexp
+	if filter.Pattern != "" {
+		pattern, err = regexp.Compile("(?i)" + filter.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search pattern: %w", err)
+		}
+	}
+
+	var matches []models.HistoryEntry
+	for _, entry := range entries {
+		if filter.Tool != "" && entry.Tool != filter.Tool {
+			continue
+		}
+		if filter.Profile != "" && entry.Profile != filter.Profile {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(entry.Payload) && !pattern.MatchString(entry.Address) {
+			continue
+		}
+		matches = append(matches, entry)
+	}
+
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[len(matches)-filter.Limit:]
+	}
+	return matches, nil
+}
+
+func Get(id int) (*models.HistoryEntry, error) {
+	entries, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.ID == id {
+			return &entry, nil
+		}
+	}
+	return nil, fmt.Errorf("history entry %d not found", id)
+}
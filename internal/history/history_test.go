@@ -0,0 +1,161 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/options"
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		secrets []string
+		want    string
+	}{
+		{"groovy assignment", `user.password = 'hunter2'`, nil, `user.password = ********`},
+		{"property", "db.password=hunter2\nother=1", nil, "db.password=********\nother=1"},
+		{"environment variable", "HYBRIS_PASSWORD=hunter2", nil, "HYBRIS_PASSWORD=********"},
+		{"login form", "j_username=admin&j_password=nimda&x=1", nil, "j_username=admin&j_password=********&x=1"},
+		{"json key", `{"passwd": "hunter2"}`, nil, `{"passwd": ********}`},
+		{"setter", `user.setEncodedPassword("hunter2")`, nil, `user.setEncodedPassword(********)`},
+		{"bean name", `def passwordService = spring.getBean('passwordService')`, nil, `def passwordService = spring.getBean('passwordService')`},
+		{"identifier suffix", `passwordEncoder: bcrypt`, nil, `passwordEncoder: bcrypt`},
+		{"configured password", "login as admin/s3cret", []string{"s3cret"}, "login as admin/********"},
+		{"short secrets are ignored", "a=1", []string{"1"}, "a=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.text, tt.secrets...); got != tt.want {
+				t.Errorf("Redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendEntryIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	t.Setenv("HACTOOLS_HISTORY", path)
+
+	const count = 20
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := appendEntry(&models.HistoryEntry{Tool: "xg", Payload: "return 1"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != count {
+		t.Fatalf("got %d entries, want %d", len(entries), count)
+	}
+
+	seen := make(map[int]bool)
+	for _, entry := range entries {
+		if seen[entry.ID] {
+			t.Errorf("duplicate id %d", entry.ID)
+		}
+		seen[entry.ID] = true
+	}
+
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestRecordHash(t *testing.T) {
+	t.Setenv("HACTOOLS_HISTORY", filepath.Join(t.TempDir(), "history.jsonl"))
+	t.Setenv("HACTOOLS_NO_HISTORY", "")
+	conf := options.Config{Password: "s3cret"}
+
+	Record(conf, &models.HistoryEntry{Tool: "xg", Payload: "return 1"}, time.Now(), nil)
+	Record(conf, &models.HistoryEntry{Tool: "xg", Payload: "login('admin', 's3cret')"}, time.Now(), nil)
+
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].PayloadHash != Hash("return 1") {
+		t.Errorf("PayloadHash = %q, want the hash of the payload", entries[0].PayloadHash)
+	}
+	if entries[1].PayloadHash != "" {
+		t.Errorf("PayloadHash = %q for a redacted payload, want none", entries[1].PayloadHash)
+	}
+}
+
+func TestLastID(t *testing.T) {
+	large := make([]byte, 3*tailChunk)
+	for i := range large {
+		large[i] = 'x'
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"missing file", "", 0},
+		{"single entry", `{"id":1}` + "\n", 1},
+		{"trailing blank lines", `{"id":1}` + "\n" + `{"id":2}` + "\n\n\n", 2},
+		{"last entry larger than a chunk", `{"id":1}` + "\n" + `{"id":7,"payload":"` + string(large) + `"}` + "\n", 7},
+		{"malformed last line", `{"id":4}` + "\n" + `{"id":5}` + "\n{broken\n", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.jsonl")
+			t.Setenv("HACTOOLS_HISTORY", path)
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := lastID(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("lastID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeList(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"json", EncodeList([]string{"/^a{1,3}$/", "code"}), []string{"/^a{1,3}$/", "code"}},
+		{"legacy comma separated", "code,name", []string{"code", "name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeList(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/spf13/cobra"
+)
+
+// Resolve renders the named snippet with its default parameters. It returns
+// false when the library has no snippet with that name.
+func Resolve(opts models.LibraryConfig, name string) (string, bool, error) {
+	snippet, err := Find(*opts.Dir, name, opts.Extensions)
+	if err != nil || snippet == nil {
+		return "", false, err
+	}
+
+	content, err := prepare(opts, snippet, nil)
+	if err != nil {
+		return "", true, err
+	}
+	return content, true, nil
+}
+
+// prepare selects the snippet and either hands its parameters to BindFunc
+// or substitutes them into the content.
+func prepare(opts models.LibraryConfig, snippet *Snippet, values map[string]string) (string, error) {
+	if opts.SelectFunc != nil {
+		if err := opts.SelectFunc(snippet.Path); err != nil {
+			return "", err
+		}
+	}
+
+	params, err := snippet.Bind(values)
+	if err != nil {
+		return "", err
+	}
+	if opts.BindFunc != nil && opts.BindFunc(params) {
+		return snippet.Content, nil
+	}
+	return snippet.substitute(params), nil
+}
+
+func CreateLibraryCommands(opts models.LibraryConfig) []*cobra.Command {
+	return []*cobra.Command{
+		createRunCommand(opts),
+		createListCommand(opts),
+	}
+}
+
+func createRunCommand(opts models.LibraryConfig) *cobra.Command {
+	var params []string
+
+	cmd := &cobra.Command{
+		Use:   "run [name]",
+		Short: "Run a named snippet from the library",
+		Long: `Runs a snippet from the library directory (--library or $HACTOOLS_LIBRARY).
+Snippets declare their parameters in the leading comment block with
+"@param name[=default] description" and reference them as ${name}.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snippet, err := Find(*opts.Dir, args[0], opts.Extensions)
+			if err != nil {
+				return err
+			}
+			if snippet == nil {
+				return fmt.Errorf("snippet %q not found in %s", args[0], *opts.Dir)
+			}
+
+			values, err := ParseParams(params)
+			if err != nil {
+				return err
+			}
+
+			content, err := prepare(opts, snippet, values)
+			if err != nil {
+				return err
+			}
+
+			return opts.ExecutorFunc(content)
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&params, "param", "P", nil, "Snippet parameter as name=value (repeatable)")
+
+	return cmd
+}
+
+func createListCommand(opts models.LibraryConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the snippets available in the library",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snippets, err := Load(*opts.Dir, opts.Extensions)
+			if err != nil {
+				return err
+			}
+
+			if len(snippets) == 0 {
+				fmt.Printf("No snippets found in %s\n", *opts.Dir)
+				return nil
+			}
+
+			table := output.NewTable([]string{"Name", "Description", "Params"})
+
+			for _, snippet := range snippets {
+				table.Append([]string{snippet.Name, snippet.Description, formatParams(snippet.Params)})
+			}
+
+			table.Render()
+			return nil
+		},
+	}
+}
+
+func formatParams(params []Param) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		if param.Required {
+			parts[i] = param.Name
+		} else {
+			parts[i] = fmt.Sprintf("%s=%s", param.Name, param.Default)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
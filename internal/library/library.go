@@ -0,0 +1,242 @@
+package library
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Param struct {
+	Name        string
+	Default     string
+	Description string
+	Required    bool
+}
+
+type Snippet struct {
+	Name        string
+	Path        string
+	Description string
+	Params      []Param
+	Content     string
+}
+
+var commentPrefixes = []string{"--", "//", "#"}
+
+var placeholderPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// Load reads every snippet below dir whose extension is one of extensions.
+// Snippet names are their path relative to dir, without the extension.
+func Load(dir string, extensions []string) ([]*Snippet, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var snippets []*Snippet
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !hasExtension(path, extensions) {
+			return nil
+		}
+
+		snippet, err := loadSnippet(dir, path)
+		if err != nil {
+			return err
+		}
+		snippets = append(snippets, snippet)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read library %s: %w", dir, err)
+	}
+
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Name < snippets[j].Name
+	})
+
+	return snippets, nil
+}
+
+// Find looks up a snippet by name. It returns nil without an error when the
+// library has no snippet with that name.
+func Find(dir, name string, extensions []string) (*Snippet, error) {
+	if name == "" || strings.ContainsAny(name, "\n\r") {
+		return nil, nil
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve library %s: %w", dir, err)
+	}
+
+	for _, ext := range extensions {
+		path := filepath.Join(root, filepath.FromSlash(name)+ext)
+		if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return nil, nil
+		}
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return loadSnippet(root, path)
+		}
+	}
+
+	return nil, nil
+}
+
+func loadSnippet(dir, path string) (*Snippet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve snippet name: %w", err)
+	}
+
+	description, params := ParseFrontMatter(string(data))
+
+	return &Snippet{
+		Name:        filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))),
+		Path:        path,
+		Description: description,
+		Params:      params,
+		Content:     string(data),
+	}, nil
+}
+
+// ParseFrontMatter reads the @description and @param tags from the leading
+// comment block of a snippet. A parameter is written as
+// "@param name[=default] description"; parameters without a default are
+// required.
+func ParseFrontMatter(content string) (string, []Param) {
+	var description string
+	var params []Param
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		text, ok := stripComment(line)
+		if !ok {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(text, "@description"):
+			description = strings.TrimSpace(strings.TrimPrefix(text, "@description"))
+		case strings.HasPrefix(text, "@param"):
+			if param, ok := parseParam(strings.TrimSpace(strings.TrimPrefix(text, "@param"))); ok {
+				params = append(params, param)
+			}
+		}
+	}
+
+	return description, params
+}
+
+func parseParam(text string) (Param, bool) {
+	fields := strings.SplitN(text, " ", 2)
+	if fields[0] == "" {
+		return Param{}, false
+	}
+
+	param := Param{Required: true}
+	if len(fields) > 1 {
+		param.Description = strings.TrimSpace(fields[1])
+	}
+
+	if name, value, found := strings.Cut(fields[0], "="); found {
+		param.Name = name
+		param.Default = value
+		param.Required = false
+	} else {
+		param.Name = fields[0]
+	}
+
+	return param, true
+}
+
+func stripComment(line string) (string, bool) {
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimLeft(line, prefix[:1])), true
+		}
+	}
+	return "", false
+}
+
+// Bind resolves the declared parameters from the given values, falling back
+// to the declared defaults. Missing required and unknown parameters are
+// errors.
+func (s *Snippet) Bind(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(s.Params))
+	for _, param := range s.Params {
+		value, ok := values[param.Name]
+		if !ok {
+			if param.Required {
+				return nil, fmt.Errorf("missing required parameter %q for %s", param.Name, s.Name)
+			}
+			value = param.Default
+		}
+		resolved[param.Name] = value
+	}
+
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter %q for %s", name, s.Name)
+		}
+	}
+
+	return resolved, nil
+}
+
+// Render replaces ${name} placeholders of the declared parameters with the
+// given values, falling back to the declared defaults.
+func (s *Snippet) Render(values map[string]string) (string, error) {
+	resolved, err := s.Bind(values)
+	if err != nil {
+		return "", err
+	}
+	return s.substitute(resolved), nil
+}
+
+func (s *Snippet) substitute(resolved map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s.Content, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := resolved[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+func ParseParams(args []string) (map[string]string, error) {
+	params := make(map[string]string, len(args))
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid parameter %q (expected name=value)", arg)
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+func hasExtension(path string, extensions []string) bool {
+	ext := filepath.Ext(path)
+	for _, e := range extensions {
+		if strings.EqualFold(ext, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Salvadego/HacTools/models"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "orders"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"orders/stuck.sql", "clear.groovy", "hello.js"} {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("-- @description "+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		dir        string
+		snippet    string
+		extensions []string
+		want       string
+	}{
+		{"absolute dir", dir, "orders/stuck", []string{".sql"}, "orders/stuck"},
+		{"relative dir", ".", "orders/stuck", []string{".sql"}, "orders/stuck"},
+		{"relative subdir", "orders", "stuck", []string{".sql"}, "stuck"},
+		{"first matching extension", ".", "hello", []string{".groovy", ".js"}, "hello"},
+		{"other extension", ".", "clear", []string{".sql"}, ""},
+		{"missing", ".", "missing", []string{".sql"}, ""},
+		{"outside the library", "orders", "../clear", []string{".groovy"}, ""},
+		{"empty name", ".", "", []string{".sql"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippet, err := Find(tt.dir, tt.snippet, tt.extensions)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+
+			got := ""
+			if snippet != nil {
+				got = snippet.Name
+			}
+			if got != tt.want {
+				t.Errorf("Find() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		wantDescription string
+		wantParams      []Param
+	}{
+		{
+			name:            "sql comments",
+			content:         "-- @description Stuck orders\n-- @param status Order status\n-- @param days=3 Minimum age\nSELECT 1",
+			wantDescription: "Stuck orders",
+			wantParams: []Param{
+				{Name: "status", Description: "Order status", Required: true},
+				{Name: "days", Default: "3", Description: "Minimum age"},
+			},
+		},
+		{
+			name:            "groovy comments with empty default",
+			content:         "// @param code=\n\nreturn code",
+			wantDescription: "",
+			wantParams:      []Param{{Name: "code"}},
+		},
+		{
+			name:            "stops at the first statement",
+			content:         "# @description Impex\nINSERT_UPDATE Product;code\n# @param late",
+			wantDescription: "Impex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, params := ParseFrontMatter(tt.content)
+			if description != tt.wantDescription {
+				t.Errorf("description = %q, want %q", description, tt.wantDescription)
+			}
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("params = %+v, want %+v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	snippet := &Snippet{
+		Name:    "stuck",
+		Params:  []Param{{Name: "status", Required: true}, {Name: "days", Default: "3"}},
+		Content: "status=${status} days=${days} other=${other}",
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"defaults", map[string]string{"status": "NEW"}, "status=NEW days=3 other=${other}", false},
+		{"override default", map[string]string{"status": "NEW", "days": "5"}, "status=NEW days=5 other=${other}", false},
+		{"missing required", nil, "", true},
+		{"unknown parameter", map[string]string{"status": "NEW", "other": "x"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snippet.Render(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	snippet := &Snippet{
+		Name:   "stuck",
+		Params: []Param{{Name: "status", Required: true}, {Name: "days", Default: "3"}},
+	}
+
+	tests := []struct {
+		name    string
+		values  map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"defaults", map[string]string{"status": "NEW"}, map[string]string{"status": "NEW", "days": "3"}, false},
+		{"override default", map[string]string{"status": "NEW", "days": "5"}, map[string]string{"status": "NEW", "days": "5"}, false},
+		{"missing required", nil, nil, true},
+		{"unknown parameter", map[string]string{"status": "NEW", "other": "x"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snippet.Bind(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Bind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	snippet := &Snippet{
+		Name:    "stuck",
+		Path:    "stuck.groovy",
+		Params:  []Param{{Name: "days", Default: "3"}},
+		Content: "println ${days}",
+	}
+
+	var selected string
+	var bound map[string]string
+	opts := models.LibraryConfig{
+		SelectFunc: func(path string) error { selected = path; return nil },
+		BindFunc:   func(params map[string]string) bool { bound = params; return true },
+	}
+
+	got, err := prepare(opts, snippet, map[string]string{"days": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != snippet.Content || selected != snippet.Path || bound["days"] != "5" {
+		t.Errorf("prepare() = %q with %q selected and %v bound, want the unsubstituted content", got, selected, bound)
+	}
+
+	opts.BindFunc = func(map[string]string) bool { return false }
+	if got, _ := prepare(opts, snippet, nil); got != "println 3" {
+		t.Errorf("prepare() = %q, want the default substituted", got)
+	}
+}
@@ -0,0 +1,118 @@
+package loglevel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Levels are the log4j levels HAC accepts.
+var Levels = []string{"ALL", "TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "OFF"}
+
+// rootLoggers are the names the logging page uses for the root logger.
+var rootLoggers = []string{"root", "ROOT", ""}
+
+type LogLevelManager struct {
+	Client *client.HACClient
+	// Output selects how DisplayLevel prints: text or json.
+	Output string
+
+	// route is the affinity cookie of the node whose level Set changed.
+	route string
+}
+
+func NewLogLevelManager(client *client.HACClient) *LogLevelManager {
+	return &LogLevelManager{
+		Client: client,
+	}
+}
+
+// Get returns the effective level of a logger. Loggers that are not
+// configured inherit the level of their closest configured ancestor.
+func (m *LogLevelManager) Get(name string) (*models.LoggerLevel, error) {
+	loggers, err := m.Client.GetLoggers()
+	if err != nil {
+		return nil, err
+	}
+
+	if level, ok := loggers[name]; ok {
+		return &models.LoggerLevel{Name: name, Level: level}, nil
+	}
+
+	for index := strings.LastIndex(name, "."); index > 0; index = strings.LastIndex(name[:index], ".") {
+		parent := name[:index]
+		if level, ok := loggers[parent]; ok {
+			return &models.LoggerLevel{Name: name, Level: level, Parent: parent}, nil
+		}
+	}
+
+	for _, root := range rootLoggers {
+		if level, ok := loggers[root]; ok {
+			return &models.LoggerLevel{Name: name, Level: level, Parent: "root"}, nil
+		}
+	}
+	return nil, fmt.Errorf("no level found for logger %s", name)
+}
+
+// Set changes the level of a logger and returns its previous level.
+func (m *LogLevelManager) Set(name, level string) (*models.LoggerLevel, error) {
+	level = strings.ToUpper(level)
+	if !slices.Contains(Levels, level) {
+		return nil, fmt.Errorf("invalid level: %s (must be one of %s)", level, strings.Join(Levels, ", "))
+	}
+
+	previous, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.Client.SetLogLevel(name, level); err != nil {
+		return nil, err
+	}
+	m.route = m.Client.Route()
+	return previous, nil
+}
+
+// Restore sets a logger back to a level returned by Set. The session is
+// renewed first because the restore usually happens much later, pinned to
+// the node Set changed since levels are local to each node. Loggers
+// that inherited their level keep it as a level of their own, since HAC
+// cannot remove a logger configuration.
+func (m *LogLevelManager) Restore(previous *models.LoggerLevel) error {
+	m.Client.PinNode(m.route)
+	if err := m.Client.Login(); err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	if err := m.Client.SetLogLevel(previous.Name, previous.Level); err != nil {
+		return fmt.Errorf("failed to restore level of %s: %w", previous.Name, err)
+	}
+	return nil
+}
+
+func (m *LogLevelManager) DisplayLevel(level *models.LoggerLevel) error {
+	if m.Output == OutputJSON {
+		return output.JSON(level)
+	}
+
+	if level.Parent != "" {
+		fmt.Printf("%s: %s (inherited from %s)\n", level.Name, level.Level, level.Parent)
+	} else {
+		fmt.Printf("%s: %s\n", level.Name, level.Level)
+	}
+	return nil
+}
+
+// DisplayChange prints change as a single JSON document.
+func (m *LogLevelManager) DisplayChange(change *models.LoggerLevelChange) error {
+	return output.JSON(change)
+}
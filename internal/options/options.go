@@ -2,14 +2,22 @@ package options
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
 type Config struct {
-	Address  string
-	User     string
-	Password string
+	Address      string
+	User         string
+	Password     string
+	Library      string
+	Profile      string
+	Environment  string
+	Node         string
+	NoHistory    bool
+	Timings      bool
+	YesIMeanProd bool
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -19,12 +27,33 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func ConfigDir() string {
+	if dir, exists := os.LookupEnv("XDG_CONFIG_HOME"); exists && dir != "" {
+		return filepath.Join(dir, "hactools")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hactools")
+	}
+	return filepath.Join(home, ".config", "hactools")
+}
+
 func GetDefaults(cmd *cobra.Command, conf *Config) {
 	defaultAddress := getEnvOrDefault("HYBRIS_HAC_URL", "https://localhost:9002/hac")
 	defaultUser := getEnvOrDefault("HYBRIS_USER", "admin")
 	defaultPassword := getEnvOrDefault("HYBRIS_PASSWORD", "nimda")
+	defaultLibrary := getEnvOrDefault("HACTOOLS_LIBRARY", filepath.Join(ConfigDir(), "library"))
 
 	cmd.PersistentFlags().StringVarP(&conf.Address, "address", "s", defaultAddress, "HAC address (default: $HYBRIS_HAC_URL)")
 	cmd.PersistentFlags().StringVarP(&conf.User, "user", "u", defaultUser, "Username for HAC (default: $HYBRIS_USER)")
 	cmd.PersistentFlags().StringVarP(&conf.Password, "password", "p", defaultPassword, "Password for HAC (default: $HYBRIS_PASSWORD)")
+	conf.Profile = os.Getenv("HACCLI_ACTIVE_CLIENT")
+	cmd.PersistentFlags().StringVar(&conf.Environment, "environment", os.Getenv("HYBRIS_ENV"), "Environment class of the target, e.g. dev or prod (default: $HYBRIS_ENV)")
+	cmd.PersistentFlags().StringVar(&conf.Node, "node", os.Getenv("HYBRIS_NODE"), "Pin requests to a cluster node: its affinity cookie (value or name=value) or HAC URL (default: $HYBRIS_NODE)")
+	cmd.PersistentFlags().BoolVar(&conf.YesIMeanProd, "yes-i-mean-prod", false, "Skip the confirmation of mutating operations on protected environments")
+
+	cmd.PersistentFlags().StringVar(&conf.Library, "library", defaultLibrary, "Snippet library directory (default: $HACTOOLS_LIBRARY)")
+	cmd.PersistentFlags().BoolVar(&conf.Timings, "timings", false, "Print a timing breakdown of login, requests, parsing and analysis")
+	cmd.PersistentFlags().BoolVar(&conf.NoHistory, "no-history", false, "Do not record this execution in the history (default: $HACTOOLS_NO_HISTORY)")
 }
@@ -0,0 +1,86 @@
+package platform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+// The init methods of HAC's Platform pages.
+const (
+	MethodUpdate = "UPDATE"
+	MethodInit   = "INIT"
+)
+
+type PlatformManager struct {
+	Client *client.HACClient
+}
+
+func NewPlatformManager(client *client.HACClient) *PlatformManager {
+	return &PlatformManager{
+		Client: client,
+	}
+}
+
+// NewRequest builds the form of an update or initialization. The project
+// data of an extension is selected by its extension_sample parameter, as on
+// the Platform pages.
+func NewRequest(method string, opts models.InitUpdateOptions) (models.InitUpdateRequest, error) {
+	request := models.InitUpdateRequest{
+		InitMethod:          method,
+		DropTables:          method == MethodInit,
+		ClearHMC:            opts.ClearHMC,
+		CreateEssentialData: opts.Essential,
+		LocalizeTypes:       opts.LocalizeTypes,
+		AllParameters:       make(map[string][]string),
+	}
+
+	for _, extension := range opts.ProjectData {
+		extension = strings.TrimSpace(extension)
+		if extension == "" {
+			continue
+		}
+		request.AllParameters[extension+"_sample"] = []string{"true"}
+	}
+
+	for _, parameter := range opts.Parameters {
+		key, value, ok := strings.Cut(parameter, "=")
+		if !ok || !strings.Contains(key, "_") {
+			return request, fmt.Errorf("invalid parameter: %s (must be extension_parameter=value)", parameter)
+		}
+		request.AllParameters[key] = append(request.AllParameters[key], value)
+	}
+
+	return request, nil
+}
+
+// Run updates or initializes the platform, copying the log to w as the
+// server writes it, and fails when the server reports a failure.
+func (m *PlatformManager) Run(request models.InitUpdateRequest, w io.Writer) (*models.InitUpdateResult, error) {
+	result, err := m.Client.InitUpdate(request, w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", Name(request.InitMethod), err)
+	}
+
+	if !result.Success {
+		return result, fmt.Errorf("%s failed, see the log above", Name(request.InitMethod))
+	}
+	return result, nil
+}
+
+// Name describes an init method for messages.
+func Name(method string) string {
+	if method == MethodInit {
+		return "initialization"
+	}
+	return "update"
+}
+
+// DisplayRequest prints the form that would be sent, for dry runs.
+func DisplayRequest(request models.InitUpdateRequest) error {
+	return output.JSON(request)
+}
@@ -0,0 +1,193 @@
+package properties
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+type PropertyManager struct {
+	Client *client.HACClient
+	// Output selects how the Display functions print: text or json.
+	Output string
+}
+
+func NewPropertyManager(client *client.HACClient) *PropertyManager {
+	return &PropertyManager{
+		Client: client,
+	}
+}
+
+// Get returns the properties whose key matches pattern: an exact key, a
+// glob such as solr.* or a regular expression enclosed in slashes.
+func (m *PropertyManager) Get(pattern string) (map[string]string, error) {
+	properties, err := m.Client.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := Matcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]string)
+	for key, value := range properties {
+		if match(key) {
+			matched[key] = value
+		}
+	}
+	return matched, nil
+}
+
+// Set stores a property and returns its previous value, or nil when it was
+// not set before. The configuration is read again afterwards, since the
+// configuration store answers the same way whether or not it kept the value.
+func (m *PropertyManager) Set(key, value string) (*string, error) {
+	properties, err := m.Client.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *string
+	if old, ok := properties[key]; ok {
+		previous = &old
+	}
+
+	if err := m.Client.SetConfiguration(key, value); err != nil {
+		return nil, err
+	}
+
+	properties, err = m.Client.GetConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify property %s: %w", key, err)
+	}
+	stored, ok := properties[key]
+	if !ok {
+		return nil, fmt.Errorf("property %s was not stored", key)
+	}
+	if stored != value {
+		return nil, fmt.Errorf("property %s was not stored, the server reads %q", key, stored)
+	}
+	return previous, nil
+}
+
+// Matcher compiles a key pattern for Get. Patterns without wildcards match
+// the key exactly.
+func Matcher(pattern string) (func(string) bool, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	return func(key string) bool {
+		ok, _ := path.Match(pattern, key)
+		return ok
+	}, nil
+}
+
+// Diff compares two configurations and returns the properties starting with
+// prefix that are missing on one side or have different values, by key.
+func Diff(left, right map[string]string, prefix string) []models.PropertyDiff {
+	keys := make(map[string]bool)
+	for key := range left {
+		keys[key] = true
+	}
+	for key := range right {
+		keys[key] = true
+	}
+
+	var diffs []models.PropertyDiff
+	for key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		leftValue, inLeft := left[key]
+		rightValue, inRight := right[key]
+		if inLeft && inRight && leftValue == rightValue {
+			continue
+		}
+
+		diff := models.PropertyDiff{Key: key}
+		if inLeft {
+			diff.Left = &leftValue
+		}
+		if inRight {
+			diff.Right = &rightValue
+		}
+		diffs = append(diffs, diff)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+func (m *PropertyManager) DisplayProperties(properties map[string]string) error {
+	if m.Output == OutputJSON {
+		return output.JSON(properties)
+	}
+
+	if len(properties) == 0 {
+		fmt.Println("No matching properties found")
+		return nil
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	table := output.NewTable([]string{"Key", "Value"})
+	for _, key := range keys {
+		table.Append([]string{key, properties[key]})
+	}
+	table.Render()
+	return nil
+}
+
+func (m *PropertyManager) DisplayDiff(diffs []models.PropertyDiff, left, right string) error {
+	if m.Output == OutputJSON {
+		if diffs == nil {
+			diffs = []models.PropertyDiff{}
+		}
+		return output.JSON(diffs)
+	}
+
+	if len(diffs) == 0 {
+		fmt.Println("No differences found")
+		return nil
+	}
+
+	table := output.NewTable([]string{"Key", left, right})
+	for _, diff := range diffs {
+		table.Append([]string{diff.Key, formatValue(diff.Left), formatValue(diff.Right)})
+	}
+	table.Render()
+	return nil
+}
+
+func formatValue(value *string) string {
+	if value == nil {
+		return "<unset>"
+	}
+	return *value
+}
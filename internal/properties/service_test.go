@@ -0,0 +1,37 @@
+package properties
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	left := map[string]string{
+		"mail.smtp.host":    "smtp.dev",
+		"mail.smtp.port":    "25",
+		"mail.from":         "dev@example.com",
+		"cronjob.timertask": "true",
+	}
+	right := map[string]string{
+		"mail.smtp.host": "smtp.prod",
+		"mail.smtp.port": "25",
+		"mail.smtp.user": "mailer",
+	}
+
+	diffs := Diff(left, right, "mail.smtp.")
+	if len(diffs) != 2 {
+		t.Fatalf("Diff() = %+v, want 2 differences", diffs)
+	}
+
+	changed, added := diffs[0], diffs[1]
+	if changed.Key != "mail.smtp.host" || changed.Left == nil || *changed.Left != "smtp.dev" || changed.Right == nil || *changed.Right != "smtp.prod" {
+		t.Errorf("Diff()[0] = %+v, want mail.smtp.host smtp.dev -> smtp.prod", changed)
+	}
+	if added.Key != "mail.smtp.user" || added.Left != nil || added.Right == nil || *added.Right != "mailer" {
+		t.Errorf("Diff()[1] = %+v, want mail.smtp.user only on the right", added)
+	}
+
+	if got := Diff(left, right, ""); len(got) != 4 || got[0].Key != "cronjob.timertask" || got[0].Right != nil {
+		t.Errorf("Diff() without prefix = %+v, want 4 sorted differences", got)
+	}
+	if got := Diff(left, left, ""); got != nil {
+		t.Errorf("Diff() of equal configurations = %+v, want none", got)
+	}
+}
@@ -0,0 +1,172 @@
+package regioncache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Salvadego/HacTools/internal/client"
+	"github.com/Salvadego/HacTools/internal/logger"
+	"github.com/Salvadego/HacTools/internal/output"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+type RegionCacheMonitor struct {
+	Client *client.HACClient
+	// Output selects how DisplayRegions prints: text or json.
+	Output string
+}
+
+func NewRegionCacheMonitor(client *client.HACClient) *RegionCacheMonitor {
+	return &RegionCacheMonitor{
+		Client: client,
+	}
+}
+
+// Stats returns the region caches ordered by name.
+func (m *RegionCacheMonitor) Stats() ([]models.CacheRegion, error) {
+	regions, err := m.Client.GetCacheRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Name < regions[j].Name })
+	return regions, nil
+}
+
+// Clear clears one region cache, or all of them when region is empty, and
+// returns the names of the cleared regions.
+func (m *RegionCacheMonitor) Clear(region string) ([]string, error) {
+	regions, err := m.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, r := range regions {
+		if region == "" || r.Name == region {
+			names = append(names, r.Name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("region cache %s not found", region)
+	}
+
+	for _, name := range names {
+		if err := m.Client.ClearCacheRegion(name); err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// Watch prints the region caches every interval until ctx is done. Text
+// output redraws the table with the changes since the previous refresh,
+// json output prints one document per line. A failed refresh logs in again
+// once, since watching usually outlives the session, and stays on the node
+// that was watched.
+func (m *RegionCacheMonitor) Watch(ctx context.Context, interval time.Duration) error {
+	var previous []models.CacheRegion
+	route := m.Client.Route()
+	for {
+		regions, err := m.Stats()
+		if err != nil {
+			logger.Info("Refreshing region caches failed, logging in again: %v", err)
+			m.Client.PinNode(route)
+			if err := m.Client.Login(); err != nil {
+				return fmt.Errorf("failed to login: %w", err)
+			}
+			if regions, err = m.Stats(); err != nil {
+				return err
+			}
+		}
+
+		if m.Output == OutputJSON {
+			if err := json.NewEncoder(os.Stdout).Encode(regions); err != nil {
+				return fmt.Errorf("failed to encode output: %w", err)
+			}
+		} else {
+			fmt.Print(clearScreen)
+			fmt.Printf("Region caches at %s, every %s (Ctrl-C to stop)\n\n", time.Now().Format("15:04:05"), interval)
+			m.displayTable(regions, previous)
+		}
+		previous = regions
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (m *RegionCacheMonitor) DisplayRegions(regions []models.CacheRegion) error {
+	if m.Output == OutputJSON {
+		return output.JSON(regions)
+	}
+
+	if len(regions) == 0 {
+		fmt.Println("No region caches found")
+		return nil
+	}
+
+	m.displayTable(regions, nil)
+	return nil
+}
+
+// displayTable prints the regions. Counters show their change since
+// previous when a previous state of the region is given.
+func (m *RegionCacheMonitor) displayTable(regions, previous []models.CacheRegion) {
+	before := make(map[string]models.CacheRegion, len(previous))
+	for _, region := range previous {
+		before[region.Name] = region
+	}
+
+	table := output.NewTable([]string{"Region", "Size", "Max", "Fill", "Hits", "Misses", "Hit ratio", "Evictions", "Invalidations"})
+	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+
+	for _, region := range regions {
+		old, ok := before[region.Name]
+		counter := func(value, oldValue int64) string {
+			if !ok {
+				return strconv.FormatInt(value, 10)
+			}
+			return fmt.Sprintf("%d (%+d)", value, value-oldValue)
+		}
+
+		table.Append([]string{
+			region.Name,
+			strconv.FormatInt(region.Size, 10),
+			strconv.FormatInt(region.MaxEntries, 10),
+			percent(region.Size, region.MaxEntries),
+			counter(region.Hits, old.Hits),
+			counter(region.Misses, old.Misses),
+			percent(region.Hits, region.Hits+region.Misses),
+			counter(region.Evictions, old.Evictions),
+			counter(region.Invalidations, old.Invalidations),
+		})
+	}
+	table.Render()
+}
+
+func percent(part, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+}
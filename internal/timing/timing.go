@@ -0,0 +1,85 @@
+package timing
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Timings accumulates named durations. A nil *Timings records nothing, so
+// callers can track unconditionally.
+type Timings struct {
+	mu      sync.Mutex
+	start   time.Time
+	order   []string
+	entries map[string]*entry
+}
+
+type entry struct {
+	total time.Duration
+	calls int
+}
+
+func New() *Timings {
+	return &Timings{
+		start:   time.Now(),
+		entries: make(map[string]*entry),
+	}
+}
+
+// Track records the time elapsed since start under name. It is meant to be
+// deferred: defer t.Track("login", time.Now()).
+func (t *Timings) Track(name string, start time.Time) {
+	t.Add(name, time.Since(start))
+}
+
+func (t *Timings) Add(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[name]
+	if !ok {
+		e = &entry{}
+		t.entries[name] = e
+		t.order = append(t.order, name)
+	}
+	e.total += d
+	e.calls++
+}
+
+func (t *Timings) Print(w io.Writer) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	width := len("total")
+	for _, name := range t.order {
+		width = max(width, len(name))
+	}
+
+	fmt.Fprintln(w, "\n=== TIMINGS ===")
+	for _, name := range t.order {
+		e := t.entries[name]
+		if e.calls > 1 {
+			fmt.Fprintf(w, "%-*s  %10s  (%d calls)\n", width, name, round(e.total), e.calls)
+		} else {
+			fmt.Fprintf(w, "%-*s  %10s\n", width, name, round(e.total))
+		}
+	}
+	fmt.Fprintf(w, "%-*s  %10s\n", width, "total", round(time.Since(t.start)))
+}
+
+func round(d time.Duration) time.Duration {
+	if d > time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
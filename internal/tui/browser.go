@@ -0,0 +1,510 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Salvadego/HacTools/internal/flexsearch"
+	"github.com/Salvadego/HacTools/models"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	maxColumnWidth = 40
+	columnPadding  = 3
+)
+
+const helpText = "←→↑↓ move  / search  n/N next/prev  s sort  x hide  X show all  </> move col  y/Y copy cell/row  ⏎ drill  ⌫ back  q quit"
+
+var (
+	headerStyle   = tcell.StyleDefault.Bold(true).Reverse(true)
+	selectedStyle = tcell.StyleDefault.Reverse(true)
+	cursorStyle   = tcell.StyleDefault.Background(tcell.ColorDarkCyan).Foreground(tcell.ColorWhite)
+	matchStyle    = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	statusStyle   = tcell.StyleDefault.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite)
+)
+
+// Browser is an interactive viewer for FlexibleSearch results.
+type Browser struct {
+	Executor *flexsearch.FlexSearchExecutor
+	Options  models.FlexExecuteOptions
+
+	screen    tcell.Screen
+	views     []*view
+	status    string
+	searching bool
+	showHelp  bool
+}
+
+type view struct {
+	title  string
+	result *models.FlexSearchResponse
+	cells  [][]string
+	widths []int
+
+	columns []int
+	rows    []int
+
+	row, col  int
+	top, left int
+
+	sortCol  int
+	sortDesc bool
+	search   string
+}
+
+func NewBrowser(executor *flexsearch.FlexSearchExecutor, opts models.FlexExecuteOptions) *Browser {
+	return &Browser{
+		Executor: executor,
+		Options:  opts,
+	}
+}
+
+func (b *Browser) Browse(title string, result *models.FlexSearchResponse) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return fmt.Errorf("failed to create screen: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("failed to initialize screen: %w", err)
+	}
+	defer screen.Fini()
+
+	b.screen = screen
+	b.views = []*view{newView(title, result)}
+
+	for {
+		b.draw()
+
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			if b.searching {
+				b.handleSearchKey(ev)
+				continue
+			}
+			if quit := b.handleKey(ev); quit {
+				return nil
+			}
+		}
+	}
+}
+
+func newView(title string, result *models.FlexSearchResponse) *view {
+	v := &view{
+		title:   title,
+		result:  result,
+		cells:   make([][]string, len(result.ResultList)),
+		widths:  make([]int, len(result.Headers)),
+		columns: make([]int, len(result.Headers)),
+		rows:    make([]int, len(result.ResultList)),
+		sortCol: -1,
+	}
+
+	for i, header := range result.Headers {
+		v.columns[i] = i
+		v.widths[i] = runewidth.StringWidth(displayHeader(header))
+	}
+
+	for rowIdx, row := range result.ResultList {
+		v.rows[rowIdx] = rowIdx
+		v.cells[rowIdx] = make([]string, len(row))
+		for colIdx, cell := range row {
+			text := strings.Join(strings.Fields(flexsearch.FormatCell(result, cell)), " ")
+			v.cells[rowIdx][colIdx] = text
+			if colIdx < len(v.widths) {
+				v.widths[colIdx] = max(v.widths[colIdx], min(runewidth.StringWidth(text), maxColumnWidth))
+			}
+		}
+	}
+
+	return v
+}
+
+func (b *Browser) current() *view {
+	return b.views[len(b.views)-1]
+}
+
+func (b *Browser) handleKey(ev *tcell.EventKey) bool {
+	v := b.current()
+	b.status = ""
+
+	switch ev.Key() {
+	case tcell.KeyCtrlC:
+		return true
+	case tcell.KeyUp:
+		v.moveRow(-1)
+	case tcell.KeyDown:
+		v.moveRow(1)
+	case tcell.KeyLeft:
+		v.moveCol(-1)
+	case tcell.KeyRight:
+		v.moveCol(1)
+	case tcell.KeyPgUp:
+		v.moveRow(-b.pageSize())
+	case tcell.KeyPgDn:
+		v.moveRow(b.pageSize())
+	case tcell.KeyHome:
+		v.moveRow(-len(v.rows))
+	case tcell.KeyEnd:
+		v.moveRow(len(v.rows))
+	case tcell.KeyEnter:
+		b.drill()
+	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyEscape:
+		if len(b.views) > 1 {
+			b.views = b.views[:len(b.views)-1]
+		}
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'q':
+			return true
+		case 'k':
+			v.moveRow(-1)
+		case 'j':
+			v.moveRow(1)
+		case 'h':
+			v.moveCol(-1)
+		case 'l':
+			v.moveCol(1)
+		case 'g':
+			v.moveRow(-len(v.rows))
+		case 'G':
+			v.moveRow(len(v.rows))
+		case '0':
+			v.moveCol(-len(v.columns))
+		case '$':
+			v.moveCol(len(v.columns))
+		case '/':
+			b.searching = true
+			v.search = ""
+		case 'n':
+			b.findMatch(1, false)
+		case 'N':
+			b.findMatch(-1, false)
+		case 's':
+			v.sortByCurrentColumn()
+		case 'x':
+			v.hideCurrentColumn()
+		case 'X':
+			v.showAllColumns()
+		case '<':
+			v.moveCurrentColumn(-1)
+		case '>':
+			v.moveCurrentColumn(1)
+		case 'y':
+			b.copy(v.currentCell())
+		case 'Y':
+			b.copy(strings.Join(v.currentRow(), "\t"))
+		case '?':
+			b.showHelp = !b.showHelp
+		}
+	}
+
+	return false
+}
+
+func (b *Browser) handleSearchKey(ev *tcell.EventKey) {
+	v := b.current()
+
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		b.searching = false
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		b.searching = false
+		v.search = ""
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if v.search != "" {
+			runes := []rune(v.search)
+			v.search = string(runes[:len(runes)-1])
+		}
+	case tcell.KeyRune:
+		v.search += string(ev.Rune())
+		b.findMatch(1, true)
+	}
+}
+
+// findMatch moves the cursor to the next cell containing the search text.
+// When includeCurrent is set the current cell is considered first, which
+// keeps the cursor in place while the search text is being extended.
+func (b *Browser) findMatch(direction int, includeCurrent bool) {
+	v := b.current()
+	if v.search == "" || len(v.rows) == 0 || len(v.columns) == 0 {
+		return
+	}
+
+	total := len(v.rows) * len(v.columns)
+	position := v.row*len(v.columns) + v.col
+
+	start := direction
+	if includeCurrent {
+		start = 0
+	}
+
+	for i := 0; i < total; i++ {
+		offset := start + i*direction
+		p := ((position+offset)%total + total) % total
+		row, col := p/len(v.columns), p%len(v.columns)
+		if v.matches(v.cells[v.rows[row]][v.columns[col]]) {
+			v.row, v.col = row, col
+			return
+		}
+	}
+
+	b.status = fmt.Sprintf("Pattern not found: %s", v.search)
+}
+
+func (b *Browser) drill() {
+	v := b.current()
+	if len(v.rows) == 0 || len(v.columns) == 0 {
+		return
+	}
+
+	pk := v.result.ResultList[v.rows[v.row]][v.columns[v.col]]
+	typeCode, ok := v.result.PKTypes[pk]
+	if !ok {
+		b.status = "Selected cell is not an analyzed PK"
+		return
+	}
+
+	query := fmt.Sprintf("SELECT * FROM {%s} WHERE {pk} = %s", typeCode, pk)
+	b.status = fmt.Sprintf("Running %s", query)
+	b.draw()
+
+	result, err := b.Executor.Execute(query, b.Options)
+	if err != nil {
+		b.status = fmt.Sprintf("Drill failed: %v", err)
+		return
+	}
+	if len(result.ResultList) == 0 {
+		b.status = fmt.Sprintf("No %s found for PK %s", typeCode, pk)
+		return
+	}
+
+	b.status = ""
+	b.views = append(b.views, newView(fmt.Sprintf("%s(%s)", typeCode, pk), result))
+}
+
+// copy puts text on the clipboard using the OSC 52 terminal escape sequence.
+// It goes through the screen so that it does not interleave with drawing.
+func (b *Browser) copy(text string) {
+	b.screen.SetClipboard([]byte(text))
+	b.status = fmt.Sprintf("Copied %d characters", len(text))
+}
+
+func (b *Browser) pageSize() int {
+	_, height := b.screen.Size()
+	return max(height-2, 1)
+}
+
+func (b *Browser) draw() {
+	v := b.current()
+	screen := b.screen
+	screen.Clear()
+
+	width, height := screen.Size()
+	bodyHeight := max(height-2, 0)
+
+	if v.row < v.top {
+		v.top = v.row
+	}
+	if v.row >= v.top+bodyHeight {
+		v.top = v.row - bodyHeight + 1
+	}
+	v.ensureColumnVisible(width)
+
+	x := 0
+	for colPos := v.left; colPos < len(v.columns) && x < width; colPos++ {
+		colIdx := v.columns[colPos]
+		colWidth := v.widths[colIdx] + columnPadding
+
+		header := displayHeader(v.result.Headers[colIdx])
+		if colIdx == v.sortCol {
+			if v.sortDesc {
+				header += " ▼"
+			} else {
+				header += " ▲"
+			}
+		}
+		drawCell(screen, x, 0, colWidth, header, headerStyle)
+
+		for y := 0; y < bodyHeight && v.top+y < len(v.rows); y++ {
+			rowPos := v.top + y
+			text := v.cells[v.rows[rowPos]][colIdx]
+
+			style := tcell.StyleDefault
+			switch {
+			case rowPos == v.row && colPos == v.col:
+				style = cursorStyle
+			case rowPos == v.row:
+				style = selectedStyle
+			case v.matches(text):
+				style = matchStyle
+			}
+			drawCell(screen, x, y+1, colWidth, text, style)
+		}
+
+		x += colWidth
+	}
+	for ; x < width; x++ {
+		screen.SetContent(x, 0, ' ', nil, headerStyle)
+	}
+
+	b.drawStatus(width, height)
+	screen.Show()
+}
+
+func (b *Browser) drawStatus(width, height int) {
+	v := b.current()
+
+	var status string
+	switch {
+	case b.searching:
+		status = "/" + v.search
+	case b.status != "":
+		status = b.status
+	case b.showHelp:
+		status = helpText
+	default:
+		breadcrumb := make([]string, len(b.views))
+		for i, view := range b.views {
+			breadcrumb[i] = view.title
+		}
+		status = fmt.Sprintf("%s │ row %d/%d │ col %d/%d │ ? help",
+			strings.Join(breadcrumb, " › "), min(v.row+1, len(v.rows)), len(v.rows),
+			min(v.col+1, len(v.columns)), len(v.columns))
+	}
+
+	drawCell(b.screen, 0, height-1, width, status, statusStyle)
+}
+
+func (v *view) moveRow(delta int) {
+	v.row = clamp(v.row+delta, 0, len(v.rows)-1)
+}
+
+func (v *view) moveCol(delta int) {
+	v.col = clamp(v.col+delta, 0, len(v.columns)-1)
+}
+
+func (v *view) ensureColumnVisible(width int) {
+	if v.col < v.left {
+		v.left = v.col
+	}
+
+	for v.left < v.col {
+		used := 0
+		for colPos := v.left; colPos <= v.col; colPos++ {
+			used += v.widths[v.columns[colPos]] + columnPadding
+		}
+		if used <= width {
+			break
+		}
+		v.left++
+	}
+}
+
+func (v *view) sortByCurrentColumn() {
+	if len(v.columns) == 0 {
+		return
+	}
+
+	colIdx := v.columns[v.col]
+	if v.sortCol == colIdx {
+		v.sortDesc = !v.sortDesc
+	} else {
+		v.sortCol = colIdx
+		v.sortDesc = false
+	}
+
+	rows := v.result.ResultList
+	sort.SliceStable(v.rows, func(i, j int) bool {
+		cmp := flexsearch.CompareValues(rows[v.rows[i]][colIdx], rows[v.rows[j]][colIdx])
+		if v.sortDesc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+func (v *view) hideCurrentColumn() {
+	if len(v.columns) <= 1 {
+		return
+	}
+
+	v.columns = append(v.columns[:v.col], v.columns[v.col+1:]...)
+	v.col = clamp(v.col, 0, len(v.columns)-1)
+}
+
+func (v *view) showAllColumns() {
+	current := -1
+	if len(v.columns) > 0 {
+		current = v.columns[v.col]
+	}
+
+	v.columns = v.columns[:0]
+	for i := range v.result.Headers {
+		v.columns = append(v.columns, i)
+		if i == current {
+			v.col = i
+		}
+	}
+}
+
+func (v *view) moveCurrentColumn(delta int) {
+	target := v.col + delta
+	if target < 0 || target >= len(v.columns) {
+		return
+	}
+
+	v.columns[v.col], v.columns[target] = v.columns[target], v.columns[v.col]
+	v.col = target
+}
+
+func (v *view) currentCell() string {
+	if len(v.rows) == 0 || len(v.columns) == 0 {
+		return ""
+	}
+	return v.cells[v.rows[v.row]][v.columns[v.col]]
+}
+
+func (v *view) currentRow() []string {
+	if len(v.rows) == 0 {
+		return nil
+	}
+
+	row := make([]string, len(v.columns))
+	for i, colIdx := range v.columns {
+		row[i] = v.cells[v.rows[v.row]][colIdx]
+	}
+	return row
+}
+
+func (v *view) matches(text string) bool {
+	return v.search != "" && strings.Contains(strings.ToLower(text), strings.ToLower(v.search))
+}
+
+func drawCell(screen tcell.Screen, x, y, width int, text string, style tcell.Style) {
+	text = runewidth.Truncate(text, width-1, "…")
+
+	col := x
+	for _, r := range text {
+		screen.SetContent(col, y, r, nil, style)
+		col += runewidth.RuneWidth(r)
+	}
+	for ; col < x+width; col++ {
+		screen.SetContent(col, y, ' ', nil, style)
+	}
+}
+
+func displayHeader(header string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(header, "p_"), "P_")
+}
+
+func clamp(value, low, high int) int {
+	if high < low {
+		return low
+	}
+	return max(low, min(value, high))
+}
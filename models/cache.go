@@ -0,0 +1,9 @@
+package models
+
+type CacheStats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Hits    int   `json:"hits"`
+	Misses  int   `json:"misses"`
+	Writes  int   `json:"writes"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// ClusterNode is a member of the cluster as seen by the node that served the
+// request. PingAge is the time since the node last pinged the cluster, in
+// milliseconds, or nil when unknown.
+type ClusterNode struct {
+	ID      int    `json:"id"`
+	Host    string `json:"host"`
+	IP      string `json:"ip"`
+	PingAge *int64 `json:"pingAge"`
+	Current bool   `json:"current"`
+	Route   string `json:"route,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type ColumnConfig struct {
+	Blacklist []string                `yaml:"blacklist"`
+	Whitelist []string                `yaml:"whitelist"`
+	Rename    map[string]string       `yaml:"rename"`
+	Types     map[string]ColumnConfig `yaml:"types"`
+}
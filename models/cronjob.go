@@ -0,0 +1,33 @@
+package models
+
+// CronJob is the state of a CronJob as reported by the server. Times are
+// milliseconds since the epoch, zero when unset.
+type CronJob struct {
+	Code      string `json:"code"`
+	Type      string `json:"type"`
+	Job       string `json:"job"`
+	Status    string `json:"status"`
+	Result    string `json:"result"`
+	Active    bool   `json:"active"`
+	StartTime int64  `json:"startTime"`
+	EndTime   int64  `json:"endTime"`
+	Node      *int   `json:"node"`
+}
+
+// CronJobRun is an entry of the execution history of a CronJob.
+type CronJobRun struct {
+	StartTime int64  `json:"startTime"`
+	EndTime   int64  `json:"endTime"`
+	Status    string `json:"status"`
+	Result    string `json:"result"`
+	User      string `json:"user"`
+	Node      *int   `json:"node"`
+}
+
+// CronJobLogs is the most recent log of a CronJob. Source names the log
+// file media, or is empty when the text was built from the job log entries.
+type CronJobLogs struct {
+	Code   string `json:"code"`
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
@@ -0,0 +1,85 @@
+package models
+
+// ThreadDump is the state of every thread of a node at Time, in
+// milliseconds since the epoch.
+type ThreadDump struct {
+	Host    string       `json:"host"`
+	Time    int64        `json:"time"`
+	Threads []ThreadInfo `json:"threads"`
+}
+
+// ThreadInfo is a thread of a dump. LockName is the monitor or synchronizer
+// the thread waits for, and LockOwner the thread holding it, when any.
+type ThreadInfo struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	State     string   `json:"state"`
+	Daemon    bool     `json:"daemon"`
+	LockName  string   `json:"lockName,omitempty"`
+	LockOwner string   `json:"lockOwner,omitempty"`
+	Stack     []string `json:"stack"`
+}
+
+// ThreadSummary groups the threads of a dump by pool and by stack.
+type ThreadSummary struct {
+	Host    string         `json:"host"`
+	Time    int64          `json:"time"`
+	Threads int            `json:"threads"`
+	States  map[string]int `json:"states"`
+	Pools   []ThreadPool   `json:"pools"`
+	Stacks  []ThreadStack  `json:"stacks"`
+}
+
+// ThreadPool counts the threads of a pool by state. The pool of a thread is
+// its name without the trailing number.
+type ThreadPool struct {
+	Name    string         `json:"name"`
+	Threads int            `json:"threads"`
+	States  map[string]int `json:"states"`
+}
+
+// ThreadStack is a group of threads in the same state with the same top
+// frames. Unchanged counts the threads that kept this stack in every dump
+// of the capture.
+type ThreadStack struct {
+	State     string   `json:"state"`
+	Threads   []string `json:"threads"`
+	Unchanged int      `json:"unchanged"`
+	Frames    []string `json:"frames"`
+}
+
+// MemoryUsage is a memory area in bytes. Max is -1 when the area has no
+// limit.
+type MemoryUsage struct {
+	Init      int64 `json:"init"`
+	Used      int64 `json:"used"`
+	Committed int64 `json:"committed"`
+	Max       int64 `json:"max"`
+}
+
+// MemoryPool is a heap or non-heap memory pool of the JVM.
+type MemoryPool struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Usage MemoryUsage `json:"usage"`
+}
+
+// GarbageCollector is the activity of a collector since the JVM started.
+// Time is the accumulated collection time in milliseconds.
+type GarbageCollector struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Time  int64  `json:"time"`
+}
+
+// MemoryInfo is the memory of a node at Time, in milliseconds since the
+// epoch.
+type MemoryInfo struct {
+	Host              string             `json:"host"`
+	Time              int64              `json:"time"`
+	Uptime            int64              `json:"uptime"`
+	Heap              MemoryUsage        `json:"heap"`
+	NonHeap           MemoryUsage        `json:"nonHeap"`
+	Pools             []MemoryPool       `json:"pools"`
+	GarbageCollectors []GarbageCollector `json:"garbageCollectors"`
+}
@@ -5,6 +5,9 @@ import "github.com/spf13/cobra"
 type EditorConfig struct {
 	FilePattern    string
 	InitialContent string
-	ExecutorFunc   func(string) error
-	CustomFlags    []func(*cobra.Command)
+	// TemplateFunc, when set, picks the file pattern and initial content at
+	// run time, after flags have been parsed.
+	TemplateFunc func() (filePattern string, initialContent string, err error)
+	ExecutorFunc func(string) error
+	CustomFlags  []func(*cobra.Command)
 }
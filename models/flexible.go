@@ -1,18 +1,51 @@
 package models
 
+import "time"
+
 type FlexExecuteOptions struct {
-	MaxCount        int
-	NoAnalyze       bool
-	ColumnBlacklist []string
-	NoBlacklist     bool
+	MaxCount  int
+	NoAnalyze bool
+	Expand    bool
+	Columns   ColumnConfig
+	KeepEmpty bool
+	Locale    string
+	CacheTTL  time.Duration
 }
 
 type FlexSearchResponse struct {
 	Headers    []string       `json:"headers"`
 	ResultList [][]string     `json:"resultList"`
 	Exception  *FlexException `json:"exception"`
+
+	ExecutionTime int64  `json:"executionTime"`
+	Query         string `json:"query"`
+
+	PKTypes  map[string]string `json:"-"`
+	Expanded map[string]string `json:"-"`
+	Cached   bool              `json:"-"`
 }
 
 type FlexException struct {
 	Message string `json:"message"`
 }
+
+type FlexTransformOptions struct {
+	Where   []string
+	GroupBy []string
+	Agg     []string
+	Sort    []string
+}
+
+type FlexStreamOptions struct {
+	PageSize   int
+	Locale     string
+	Columns    ColumnConfig
+	OnProgress func(rows int)
+}
+
+type FlexProfile struct {
+	RoundTrips    []time.Duration
+	ServerTimes   []time.Duration
+	Rows          int
+	TranslatedSQL string
+}
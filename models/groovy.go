@@ -1,8 +1,17 @@
 package models
 
+import "time"
+
 type GroovyExecuteOptions struct {
 	ScriptType string
 	Commit     bool
+	Vars       map[string]any
+	// Path is the file the script was read from, used to resolve includes
+	// relative to it and to name it in stack traces.
+	Path        string
+	IncludePath []string
+	// DryRun rolls the script back and records the items it changed.
+	DryRun bool
 }
 
 type GroovyResponse struct {
@@ -11,4 +20,81 @@ type GroovyResponse struct {
 	StacktraceText  string `json:"stacktraceText"`
 	ExceptionText   string `json:"exceptionText"`
 	Success         bool   `json:"success"`
+
+	SourceMap []SourceLine   `json:"-"`
+	Duration  time.Duration  `json:"-"`
+	Changes   []GroovyChange `json:"-"`
+	// Node names the cluster node that ran the script with --all-nodes.
+	Node string `json:"-"`
+}
+
+// GroovyChange is an item a dry run would have created, modified or removed.
+type GroovyChange struct {
+	Action     string   `json:"action"`
+	Type       string   `json:"type"`
+	PK         string   `json:"pk"`
+	Attributes []string `json:"attributes"`
+}
+
+// SourceLine is the original location and text of a line of the script that
+// was sent to the scripting console.
+type SourceLine struct {
+	File string
+	Line int
+	Text string
+}
+
+// GroovyOutput is the machine readable result of a script execution.
+// DryRun is only set by dry runs.
+type GroovyOutput struct {
+	Success    bool            `json:"success"`
+	Output     string          `json:"output"`
+	Result     any             `json:"result"`
+	Exception  string          `json:"exception,omitempty"`
+	Location   *GroovyLocation `json:"location,omitempty"`
+	Stacktrace string          `json:"stacktrace,omitempty"`
+	DurationMs int64           `json:"durationMs"`
+	Profile    string          `json:"profile,omitempty"`
+	Node       string          `json:"node,omitempty"`
+	DryRun     *GroovyDryRun   `json:"dryRun,omitempty"`
+}
+
+// GroovyDryRun is the change set of a dry run. It only holds the items saved
+// or removed through the bound modelService variable, and Tracked says so,
+// because changes made any other way are not seen.
+type GroovyDryRun struct {
+	Tracked string         `json:"tracked"`
+	Changes []GroovyChange `json:"changes"`
+}
+
+// GroovyLocation is the original source location of a script error.
+type GroovyLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+}
+
+// GroovyJob is the local record of a script submitted with --async. Route
+// is the affinity cookie of the node that runs the job, as name=value.
+type GroovyJob struct {
+	ID          string       `json:"id"`
+	Address     string       `json:"address"`
+	Profile     string       `json:"profile,omitempty"`
+	Route       string       `json:"route,omitempty"`
+	Path        string       `json:"path,omitempty"`
+	Commit      bool         `json:"commit"`
+	SubmittedAt time.Time    `json:"submittedAt"`
+	SourceMap   []SourceLine `json:"sourceMap,omitempty"`
+}
+
+// GroovyJobStatus is the state of an async job as written by the server.
+type GroovyJobStatus struct {
+	ID         string `json:"id"`
+	State      string `json:"state"`
+	Node       string `json:"node"`
+	StartedAt  int64  `json:"startedAt"`
+	FinishedAt int64  `json:"finishedAt"`
+	Result     string `json:"result"`
+	Stacktrace string `json:"stacktrace"`
+	LogSize    int64  `json:"logSize"`
 }
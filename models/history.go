@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type HistoryEntry struct {
+	ID          int               `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Tool        string            `json:"tool"`
+	Profile     string            `json:"profile,omitempty"`
+	Address     string            `json:"address"`
+	User        string            `json:"user"`
+	PayloadHash string            `json:"payloadHash"`
+	Payload     string            `json:"payload"`
+	Options     map[string]string `json:"options,omitempty"`
+	DurationMs  int64             `json:"durationMs"`
+	Rows        int               `json:"rows"`
+	Outcome     string            `json:"outcome"`
+	ExitStatus  int               `json:"exitStatus"`
+}
+
+type HistoryFilter struct {
+	Tool    string
+	Profile string
+	Pattern string
+	Limit   int
+}
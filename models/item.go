@@ -0,0 +1,14 @@
+package models
+
+type ItemDetails struct {
+	Type       string          `json:"type"`
+	PK         string          `json:"pk"`
+	Attributes []ItemAttribute `json:"attributes"`
+}
+
+type ItemAttribute struct {
+	Qualifier string `json:"qualifier"`
+	Language  string `json:"language,omitempty"`
+	Value     any    `json:"value"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type LibraryConfig struct {
+	Dir        *string
+	Extensions []string
+	// SelectFunc, when set, is called with the path of a snippet before it
+	// is run, e.g. to pick the script type from its extension.
+	SelectFunc func(path string) error
+	// BindFunc, when set, is called with the resolved parameters of a
+	// snippet after SelectFunc. Returning true means the tool binds them
+	// itself and the snippet runs without ${name} substitution.
+	BindFunc     func(params map[string]string) bool
+	ExecutorFunc func(string) error
+}
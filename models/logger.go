@@ -0,0 +1,17 @@
+package models
+
+// LoggerLevel is the level of a logger. Loggers without a level of their own
+// inherit the level of their closest configured ancestor, named in Parent.
+type LoggerLevel struct {
+	Name   string `json:"name"`
+	Level  string `json:"level"`
+	Parent string `json:"inheritedFrom,omitempty"`
+}
+
+// LoggerLevelChange is the result of changing the level of a logger. Restored
+// is set once a timed change was undone.
+type LoggerLevelChange struct {
+	Changed  LoggerLevel  `json:"changed"`
+	Previous LoggerLevel  `json:"previous"`
+	Restored *LoggerLevel `json:"restored,omitempty"`
+}
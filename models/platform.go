@@ -0,0 +1,31 @@
+package models
+
+// InitUpdateRequest is the form of HAC's Platform > Update and Platform >
+// Initialization pages. AllParameters holds the project data selection and
+// the import options of the extensions, keyed by extension_parameter.
+type InitUpdateRequest struct {
+	InitMethod          string              `json:"initMethod"`
+	DropTables          bool                `json:"dropTables"`
+	ClearHMC            bool                `json:"clearHMC"`
+	CreateEssentialData bool                `json:"createEssentialData"`
+	LocalizeTypes       bool                `json:"localizeTypes"`
+	AllParameters       map[string][]string `json:"allParameters"`
+}
+
+// InitUpdateResult is the outcome of an update or initialization and the log
+// the server wrote while it ran.
+type InitUpdateResult struct {
+	Success bool   `json:"success"`
+	Log     string `json:"log"`
+}
+
+// InitUpdateOptions selects what an update or initialization does.
+// ProjectData lists the extensions whose project data is imported and
+// Parameters holds extension_parameter=value import options.
+type InitUpdateOptions struct {
+	Essential     bool
+	ClearHMC      bool
+	LocalizeTypes bool
+	ProjectData   []string
+	Parameters    []string
+}
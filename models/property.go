@@ -0,0 +1,9 @@
+package models
+
+// PropertyDiff is a configuration property whose value differs between two
+// environments. Missing values are nil.
+type PropertyDiff struct {
+	Key   string  `json:"key"`
+	Left  *string `json:"left"`
+	Right *string `json:"right"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// CacheRegion is the state of a region cache of the platform as reported by
+// HAC's Monitoring > Cache page.
+type CacheRegion struct {
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	MaxEntries    int64  `json:"maxEntries"`
+	Hits          int64  `json:"hits"`
+	Misses        int64  `json:"misses"`
+	Evictions     int64  `json:"evictions"`
+	Invalidations int64  `json:"invalidations"`
+}